@@ -1,8 +1,12 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 func exercise02() {
 	message := "Hi 👩 and 👨"
 	fmt.Println(string([]rune(message)[3]))
+	inspect(os.Stdout, []byte(message)) // []rune(message)[3] は 3 バイト目から始まる 👩 (f0 9f 91 a9)
 }
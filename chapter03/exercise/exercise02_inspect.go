@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// バイトとルーンの確認 (inspect コマンド)
+//   go run *.go inspect [-s 文字列] [-limit 4096] [ファイル]
+//   - 文字列はバイト列なので，文字化けしたデータを見るときは「どのバイトが何の文字になっているか」を確かめる
+//   - xxd と同じ形式の16進ダンプに続けて，コードポイントごとに次を表示する
+//     - rune: []rune(s) にしたときの添字 ([]rune(message)[3] が指す文字がわかる)
+//     - offset: 何バイト目から始まるか (s[offset] でその文字の先頭のバイト)
+//     - bytes: UTF-8 のバイト列
+//     - code: U+XXXX
+//     - cat: Unicode の一般カテゴリ (Lu, Ll, Lo, Nd, Zs, So, Cc など。割り当てのない文字は Cn)
+//     - char: 文字そのもの (空白や制御文字などは '\n' や '\u200b' のように引用符で囲んでエスケープする)
+//     - invalid: 不正な UTF-8 のバイト。for range や []rune では U+FFFD (�) になる
+//       (元から U+FFFD が書かれている EF BF BD は正しい文字なので invalid にはならない)
+//   - -s で文字列，ファイル名で ファイル，どちらもなければ標準入力を調べる
+//   - -limit バイトより長い入力は先頭だけを調べる (0 なら全部)
+
+// 2文字の一般カテゴリの名前 (unicode.Categories のうち Lu, Ll などの細かい区分)
+//   - LC (Lu, Ll, Lt をまとめたもの) は細かい区分ではないので除く
+var generalCategories = func() []string {
+	var names []string
+	for name := range unicode.Categories {
+		if len(name) == 2 && name != "LC" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}()
+
+func generalCategory(r rune) string {
+	for _, name := range generalCategories {
+		if unicode.Is(unicode.Categories[name], r) {
+			return name
+		}
+	}
+	return "Cn"
+}
+
+// xxd と同じ形式の16進ダンプ (1行16バイト，2バイトごとに空白，右に ASCII で表示できる文字)
+func hexdump(w io.Writer, data []byte) {
+	for offset := 0; offset < len(data); offset += 16 {
+		line := data[offset:min(offset+16, len(data))]
+		var hexPart, text strings.Builder
+		for i := range 16 {
+			if i < len(line) {
+				fmt.Fprintf(&hexPart, "%02x", line[i])
+				if line[i] >= 0x20 && line[i] < 0x7f {
+					text.WriteByte(line[i])
+				} else {
+					text.WriteByte('.')
+				}
+			} else {
+				hexPart.WriteString("  ")
+			}
+			if i%2 == 1 {
+				hexPart.WriteByte(' ')
+			}
+		}
+		fmt.Fprintf(w, "%08x: %s %s\n", offset, hexPart.String(), text.String())
+	}
+}
+
+// コードポイントごとの一覧
+func inspectRunes(w io.Writer, data []byte) {
+	fmt.Fprintln(w, "rune  offset  bytes        code      cat  char")
+	for i, offset := 0, 0; offset < len(data); i++ {
+		r, size := utf8.DecodeRune(data[offset:])
+		raw := fmt.Sprintf("% x", data[offset:offset+size])
+		if r == utf8.RuneError && size == 1 {
+			fmt.Fprintf(w, "%4d  %6d  %-11s  %-8s  %-3s  %s\n", i, offset, raw, "-", "-", "invalid")
+		} else {
+			char := string(r)
+			if !unicode.IsGraphic(r) || unicode.IsSpace(r) {
+				char = strconv.QuoteRuneToASCII(r)
+			}
+			fmt.Fprintf(w, "%4d  %6d  %-11s  %-8s  %-3s  %s\n", i, offset, raw, fmt.Sprintf("%U", r), generalCategory(r), char)
+		}
+		offset += size
+	}
+}
+
+func inspect(w io.Writer, data []byte) {
+	hexdump(w, data)
+	fmt.Fprintln(w)
+	inspectRunes(w, data)
+}
+
+func inspectCommand(args []string) int {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	text := fs.String("s", "", "調べる文字列 (ファイルの代わり)")
+	limit := fs.Int("limit", 4096, "調べる最大のバイト数 (0 なら全部)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	var data []byte
+	if *text != "" {
+		data = []byte(*text)
+	} else {
+		var in io.Reader = os.Stdin
+		if name := fs.Arg(0); name != "" && name != "-" {
+			f, err := os.Open(name)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			defer f.Close()
+			in = f
+		}
+		if *limit > 0 {
+			in = io.LimitReader(in, int64(*limit)+1) // 1バイト多く読んで，切り捨てたかを調べる
+		}
+		var err error
+		data, err = io.ReadAll(in)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if *limit > 0 && len(data) > *limit {
+		data = data[:*limit]
+		fmt.Fprintf(os.Stderr, "先頭の %d バイトだけを調べます (末尾の文字が途中で切れていれば invalid になります)\n", *limit)
+	}
+	inspect(os.Stdout, data)
+	return 0
+}
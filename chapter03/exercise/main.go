@@ -1,6 +1,26 @@
 package main
 
+import (
+	"fmt"
+	"os"
+)
+
+// サブコマンド (go run *.go inspect のように指定する)
+//   - 戻り値は終了ステータス
+//   - 指定がなければ練習問題を順に実行する
+var commands = map[string]func(args []string) int{
+	"inspect": inspectCommand,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		cmd, ok := commands[os.Args[1]]
+		if !ok {
+			fmt.Fprintln(os.Stderr, "不明なコマンドです:", os.Args[1])
+			os.Exit(2)
+		}
+		os.Exit(cmd(os.Args[2:]))
+	}
 	exercise01()
 	exercise02()
 	exercise03()
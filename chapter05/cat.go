@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// cat の表示の変換 (example008 で使う)
+//   - -n: すべての行に行番号を付ける (番号はファイルをまたいで続く)
+//   - -s: 連続した空行を1行にまとめる
+//   - -A: 見えない文字を見えるようにする
+//     - 行末に $，タブは ^I，制御文字は ^@ 〜 ^_ と ^?
+//     - 表示できない Unicode の文字 (ゼロ幅空白や BOM など) は <U+FEFF> のように表す
+//     - 不正な UTF-8 のバイトは \xff のように表す (日本語などの正しい文字はそのまま)
+//   - 読み込みの区切りで文字が 2 つに分かれても，次の Write までバイトを持ち越して正しく扱う
+
+type catOptions struct {
+	number  bool
+	squeeze bool
+	showAll bool
+}
+
+type catPrinter struct {
+	w           *bufio.Writer
+	opts        catOptions
+	line        int    // 表示した行の数
+	atLineStart bool   // 次のバイトが行の先頭か
+	prevBlank   bool   // 直前の行が空行だったか
+	carry       []byte // 前の Write の末尾にあった，途中で切れた文字のバイト
+}
+
+func newCatPrinter(w io.Writer, opts catOptions) *catPrinter {
+	return &catPrinter{w: bufio.NewWriter(w), opts: opts, atLineStart: true}
+}
+
+func (p *catPrinter) Write(b []byte) (int, error) {
+	if p.opts == (catOptions{}) { // 変換しないならそのまま書く
+		return p.w.Write(b)
+	}
+	data := b
+	if len(p.carry) > 0 {
+		data = append(p.carry, b...)
+		p.carry = nil
+	}
+	for i := 0; i < len(data); {
+		if data[i] >= utf8.RuneSelf && !utf8.FullRune(data[i:]) {
+			p.carry = append(p.carry, data[i:]...)
+			break
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		p.rune(r, data[i:i+size])
+		i += size
+	}
+	return len(b), nil
+}
+
+// 1ファイルの終わり: 持ち越したバイトは次のファイルとつなげずに不正なバイトとして書く
+func (p *catPrinter) endFile() {
+	carry := p.carry
+	p.carry = nil
+	for i := range carry {
+		p.rune(utf8.RuneError, carry[i:i+1])
+	}
+}
+
+func (p *catPrinter) flush() error {
+	p.endFile()
+	return p.w.Flush()
+}
+
+func (p *catPrinter) rune(r rune, raw []byte) {
+	if p.atLineStart {
+		blank := r == '\n'
+		if blank && p.opts.squeeze && p.prevBlank {
+			return
+		}
+		p.prevBlank = blank
+		p.line++
+		if p.opts.number {
+			fmt.Fprintf(p.w, "%6d\t", p.line)
+		}
+		p.atLineStart = false
+	}
+	if r == '\n' {
+		if p.opts.showAll {
+			p.w.WriteByte('$')
+		}
+		p.w.WriteByte('\n')
+		p.atLineStart = true
+		return
+	}
+	if !p.opts.showAll {
+		p.w.Write(raw)
+		return
+	}
+	switch {
+	case r == utf8.RuneError && len(raw) == 1:
+		fmt.Fprintf(p.w, `\x%02x`, raw[0])
+	case r < 0x20:
+		p.w.WriteByte('^')
+		p.w.WriteByte(byte(r) + '@') // タブは ^I
+	case r == 0x7f:
+		p.w.WriteString("^?")
+	case !unicode.IsGraphic(r):
+		fmt.Fprintf(p.w, "<%U>", r)
+	default:
+		p.w.Write(raw)
+	}
+}
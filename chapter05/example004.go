@@ -1,9 +1,11 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"os"
+
+	"learning-go/internal/catalog"
+	"learning-go/internal/division"
 )
 
 // Go の関数は複数の戻り値を返せる
@@ -14,11 +16,21 @@ import (
 //   - 戻り値を受け取る変数が不要な場合は，ブランク識別子 _ を使って無視できる
 //   - 全ての戻り値を無視する場合は，そもそも受け取らなければ良い
 
+// 商と余りの定義 (truncated, floored, euclidean) は internal/division を参照
+//   - どれも num == 商 * denom + 余り を満たし，負の数を割ったときの余りの符号だけが違う
+
+var errDivByZero = catalog.NewError(msgDivByZero)
+
 func divAndRemainder(num, denom int) (int, int, error) {
+	return divAndRemainderMode(num, denom, division.Truncated)
+}
+
+func divAndRemainderMode(num, denom int, mode division.Mode) (int, int, error) {
 	if denom == 0 {
-		return 0, 0, errors.New("0で割ることはできません")
+		return 0, 0, errDivByZero
 	}
-	return num / denom, num % denom, nil
+	result, remainder := division.QuoRem(num, denom, mode)
+	return result, remainder, nil
 }
 
 func example004() {
@@ -31,4 +43,12 @@ func example004() {
 
 	result2, _, _ := divAndRemainder(5, 2)
 	fmt.Println(result2, remainder)
+
+	for _, mode := range division.Modes {
+		q1, r1, _ := divAndRemainderMode(-7, 2, mode)
+		q2, r2, _ := divAndRemainderMode(-7, -2, mode)
+		fmt.Println(q1, r1, q2, r2) // -3 -1 3 -1 / -4 1 3 -1 / -4 1 4 1
+	}
+	_, _, err = divAndRemainderMode(5, 0, division.Euclidean)
+	fmt.Println(err) // 0で割ることはできません
 }
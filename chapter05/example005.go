@@ -1,9 +1,6 @@
 package main
 
-import (
-	"errors"
-	"fmt"
-)
+import "fmt"
 
 // 名前付き戻り値
 //   - 戻り値の型の前に識別子を指定することで戻り値に名前を付けられる
@@ -17,7 +14,7 @@ import (
 func divAndRemainder2(num int, denom int) (result int, remainder int, _ error) {
 	result, remainder = 20, 30 // 適当な値を代入
 	if denom == 0 {
-		return result, remainder, errors.New("0で割ることはできません")
+		return result, remainder, errDivByZero
 	}
 	result, remainder = num/denom, num%denom
 	return result, remainder, nil
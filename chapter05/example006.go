@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"strconv"
+
+	"learning-go/internal/catalog"
 )
 
 // Go の関数は第一級である
@@ -68,7 +70,7 @@ func example006() {
 	}
 	for _, expression := range expressions {
 		if len(expression) != 3 {
-			fmt.Print(expression, " -- 不正な式です\n")
+			fmt.Print(expression, " -- ", catalog.Message(msgSyntax), "\n")
 			continue
 		}
 		p1, err := strconv.Atoi(expression[0])
@@ -79,7 +81,7 @@ func example006() {
 		op := expression[1]
 		opFunc, ok := opMap[op]
 		if !ok {
-			fmt.Print(expression, " -- ", "定義されていない演算子です: ", op, "\n")
+			fmt.Print(expression, " -- ", catalog.Message(msgUnknownOpSymbol, op), "\n")
 			continue
 		}
 		p2, err := strconv.Atoi(expression[2])
@@ -3,10 +3,17 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
+
+	"learning-go/internal/catalog"
+	"learning-go/internal/cleanup"
+	"learning-go/internal/fileio"
 )
 
 // defer: リソースのクリーンアップ処理を行う
@@ -59,39 +66,104 @@ func DoSomeInserts(ctx context.Context, db *sql.DB, value1, value2 string) (err
 	return nil
 }
 
-func getFile(name string) (*os.File, func(), error) {
-	file, err := os.Open(name)
-	if err != nil {
-		return nil, nil, err
+// example008 の表示の指定 (main の flag.Parse で読む)
+var (
+	catOpts   catOptions
+	catFollow bool
+)
+
+func init() {
+	flag.BoolVar(&catOpts.number, "n", false, "行番号を付ける")
+	flag.BoolVar(&catOpts.squeeze, "s", false, "連続した空行を1行にまとめる")
+	flag.BoolVar(&catOpts.showAll, "A", false, "見えない文字と不正な UTF-8 のバイトを見えるようにする")
+	flag.BoolVar(&catFollow, "f", false, "最後のファイルの追記を待ち続けて表示する (Ctrl-C で終わる)")
+}
+
+// ファイルを1つ w に書き出す (「-」なら標準入力)
+//   - 名前付き戻り値 err に，読み込みのエラーと後始末 (Close) のエラーをまとめて返す
+func catFile(name string, w io.Writer) (err error) {
+	var c cleanup.Stack
+	defer c.RunInto(&err) // 後始末のコード (Close のエラーも捨てない。internal/cleanup)
+	var f io.Reader = os.Stdin
+	if name != "-" {
+		// f, err := os.Open(name) // ファイルをオープン
+		file, closer, err := fileio.GetFile(name) // gzip などで圧縮されていれば展開しながら読む (internal/fileio)
+		if err != nil {
+			return err // オープンに問題あり。呼び出し元でエラーを出力する
+		}
+		// defer f.Close()
+		c.Push(closer)
+		f = file
 	}
-	return file, func() {
-		file.Close()
-	}, nil
+
+	// 2048 バイトずつ読んで出力先に書き出す
+	//   - Read と io.EOF のループと，使い回すバッファのプールは internal/fileio
+	return fileio.ReadChunks(f, 2048, func(chunk []byte) error {
+		_, err := w.Write(chunk)
+		return err
+	})
 }
 
-func example008() {
-	if len(os.Args) < 2 { // ファイル名が指定されているか
-		log.Fatal("ファイルが指定されていません")
+// 引数のファイルを順に標準出力に書き出す (cat)
+//   - go run *.go [-n] [-s] [-A] [-f] ファイル... (「-」は標準入力)
+//   - -f なら最後のファイルは終わりに着いても追記を待ち続ける (follow.go)
+//   - 読めないファイルがあってもエラーを出力して次のファイルに進む
+//   - 1つでも失敗したらエラーを返す (main が終了ステータスを 1 にする)
+func example008() error {
+	if flag.NArg() < 1 { // ファイル名が指定されているか
+		log.Fatal(catalog.Message(msgNoFile))
 	}
-	// f, err := os.Open(os.Args[1]) // ファイルをオープン
-	f, closer, err := getFile(os.Args[1])
-	if err != nil {
-		log.Fatal(err) // オープンに問題あり。エラーを出力して終了
+	ctx := context.Background()
+	if catFollow { // -f は Ctrl-C で追記を待つのをやめ，残りの処理を続ける
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
 	}
-	// defer f.Close() // 後始末のコード
-	defer closer()
-
-	data := make([]byte, 2048) // バイトのスライスを生成
-	for {
-		count, err := f.Read(data)    // 読み込んだバイト数とエラーを返す
-		os.Stdout.Write(data[:count]) // 「標準出力」に出力
+	out := newCatPrinter(os.Stdout, catOpts)
+	var errs []error
+	for i, name := range flag.Args() {
+		var err error
+		if catFollow && i == flag.NArg()-1 && name != "-" {
+			err = followFile(ctx, name, out)
+		} else {
+			err = catFile(name, out)
+		}
+		out.endFile()
 		if err != nil {
-			if err != io.EOF { // ファイルの終わりでないならば
-				log.Fatal(err) // エラーを出力して終了
-			}
-			break // forループを抜ける (ファイルの終わり)
+			out.flush() // エラーの前までの出力を先に出す
+			fmt.Fprintln(os.Stderr, err)
+			errs = append(errs, err)
 		}
 	}
+	if err := out.flush(); err != nil {
+		errs = append(errs, err)
+	}
 
 	deferExample()
+	fmt.Println(cleanupExample())
+	// cleanup: 3
+	// cleanup: 2
+	// 後始末の処理でパニックが起きました: short write
+	// close example008.go: file already closed
+	return errors.Join(errs...)
+}
+
+// 後始末のスタック: 後に積んだものから実行し，パニックと Close のエラーもまとめて返す
+func cleanupExample() (err error) {
+	var c cleanup.Stack
+	defer c.RunInto(&err)
+	file, err := os.Open("example008.go")
+	if err != nil {
+		return err
+	}
+	c.PushCloser(file) // 最後に実行される (3 で閉じた後なのでエラーになる)
+	c.Push(func() error {
+		fmt.Println("cleanup: 2")
+		panic(io.ErrShortWrite)
+	})
+	c.Push(func() error {
+		fmt.Println("cleanup: 3")
+		return file.Close()
+	})
+	return nil
 }
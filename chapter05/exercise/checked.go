@@ -0,0 +1,128 @@
+package main
+
+import (
+	"unsafe"
+
+	"learning-go/internal/catalog"
+)
+
+// 桁あふれを検出する整数演算
+//   - Go の整数演算は桁あふれしても黙って折り返す (2章 exercise03: MaxUint8 + 1 == 0 など)
+//   - checkedXxx は折り返す代わりに errOverflow をラップしたエラーを返す
+//   - saturatingXxx は折り返す代わりにその型の最小値・最大値に張り付かせる (+, -, * のみ)
+//   - strict モードの電卓は + - * / と前置の - に checkedXxx[int] を使う (最小値 / -1 と -最小値 も桁あふれ)
+//   - 2章で挙げた整数型 (int8 〜 uint64, int, uint) すべてに使えるよう型パラメータで書く
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+var errOverflow = catalog.NewError(msgOverflow)
+
+func isSigned[T integer]() bool {
+	var zero T
+	return ^zero < zero // 符号付きなら ^0 == -1
+}
+
+// 型 T の最小値と最大値
+func bounds[T integer]() (T, T) {
+	var zero T
+	if !isSigned[T]() {
+		return zero, ^zero
+	}
+	bits := unsafe.Sizeof(zero) * 8
+	minVal := T(1) << (bits - 1) // 最上位ビットだけが立った値 == 最小値
+	return minVal, ^minVal
+}
+
+func overflow[T integer](op string, a, b T) error {
+	return catalog.ErrorOf(errOverflow, msgOverflowBinary, a, op, b, a)
+}
+
+func checkedAdd[T integer](a, b T) (T, error) {
+	r := a + b
+	if (b > 0 && r < a) || (b < 0 && r > a) {
+		return r, overflow("+", a, b)
+	}
+	return r, nil
+}
+
+func checkedSub[T integer](a, b T) (T, error) {
+	r := a - b
+	if (b > 0 && r > a) || (b < 0 && r < a) {
+		return r, overflow("-", a, b)
+	}
+	return r, nil
+}
+
+func checkedMul[T integer](a, b T) (T, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+	r := a * b
+	minVal, _ := bounds[T]()
+	// 最小値 * -1 は r / b == a をすり抜けるので別に調べる
+	if isSigned[T]() && ((a == minVal && b == ^T(0)) || (b == minVal && a == ^T(0))) {
+		return r, overflow("*", a, b)
+	}
+	if r/b != a {
+		return r, overflow("*", a, b)
+	}
+	return r, nil
+}
+
+func checkedDiv[T integer](a, b T) (T, error) {
+	if b == 0 {
+		return 0, errDivByZero
+	}
+	minVal, _ := bounds[T]()
+	if isSigned[T]() && a == minVal && b == ^T(0) { // 最小値 / -1 だけは表せない
+		return a, overflow("/", a, b)
+	}
+	return a / b, nil
+}
+
+func checkedNeg[T integer](a T) (T, error) {
+	minVal, _ := bounds[T]()
+	if (isSigned[T]() && a == minVal) || (!isSigned[T]() && a != 0) {
+		return -a, catalog.ErrorOf(errOverflow, msgOverflowNeg, a, a)
+	}
+	return -a, nil
+}
+
+func saturatingAdd[T integer](a, b T) T {
+	r, err := checkedAdd(a, b)
+	if err != nil {
+		minVal, maxVal := bounds[T]()
+		if b < 0 {
+			return minVal
+		}
+		return maxVal
+	}
+	return r
+}
+
+func saturatingSub[T integer](a, b T) T {
+	r, err := checkedSub(a, b)
+	if err != nil {
+		minVal, maxVal := bounds[T]()
+		if b > 0 {
+			return minVal
+		}
+		return maxVal
+	}
+	return r
+}
+
+func saturatingMul[T integer](a, b T) T {
+	r, err := checkedMul(a, b)
+	if err != nil {
+		minVal, maxVal := bounds[T]()
+		if (a < 0) != (b < 0) { // 符号が異なれば負の方向にあふれている
+			return minVal
+		}
+		return maxVal
+	}
+	return r
+}
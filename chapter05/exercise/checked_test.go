@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// 型 T の境界の近くの値 (符号なしなら 0 以上だけ)
+func boundaryValues[T integer]() []T {
+	minVal, maxVal := bounds[T]()
+	values := []T{minVal, minVal + 1, 0, 1, 2, maxVal / 2, maxVal/2 + 1, maxVal - 1, maxVal}
+	if isSigned[T]() {
+		values = append(values, ^T(0), ^T(0)-1, minVal/2, minVal/2-1) // -1, -2
+	}
+	return values
+}
+
+func bigOf[T integer](x T) *big.Int {
+	if isSigned[T]() {
+		return big.NewInt(int64(x))
+	}
+	return new(big.Int).SetUint64(uint64(x))
+}
+
+// checked の結果を正確な結果 exact と比べる
+//   - 型に収まるならその値，収まらなければ errOverflow
+func checkResult[T integer](t *testing.T, expr string, exact *big.Int, got T, err error) {
+	t.Helper()
+	minVal, maxVal := bounds[T]()
+	switch {
+	case exact.Cmp(bigOf(minVal)) < 0 || exact.Cmp(bigOf(maxVal)) > 0:
+		if !errors.Is(err, errOverflow) {
+			t.Errorf("%s (%T) = %v, %v, want errOverflow", expr, got, got, err)
+		}
+	case err != nil || bigOf(got).Cmp(exact) != 0:
+		t.Errorf("%s (%T) = %v, %v, want %v", expr, got, got, err, exact)
+	}
+}
+
+// saturating の結果を正確な結果 exact と比べる
+//   - 型に収まるならその値，収まらなければ最小値か最大値
+func checkSaturated[T integer](t *testing.T, expr string, exact *big.Int, got T) {
+	t.Helper()
+	minVal, maxVal := bounds[T]()
+	want := minVal
+	switch {
+	case exact.Cmp(bigOf(maxVal)) > 0:
+		want = maxVal
+	case exact.Cmp(bigOf(minVal)) >= 0:
+		want = T(exact.Int64())
+		if !isSigned[T]() {
+			want = T(exact.Uint64())
+		}
+	}
+	if got != want {
+		t.Errorf("saturating %s (%T) = %v, want %v", expr, got, got, want)
+	}
+}
+
+// 境界の近くの値のすべての組で，checked と saturating を big.Int の正確な結果と比べる
+func testIntegerOps[T integer](t *testing.T) {
+	values := boundaryValues[T]()
+	for _, a := range values {
+		x := bigOf(a)
+		exact := new(big.Int).Neg(x)
+		r, err := checkedNeg(a)
+		checkResult(t, fmt.Sprintf("-(%v)", a), exact, r, err)
+		for _, b := range values {
+			y := bigOf(b)
+			for _, c := range []struct {
+				op        string
+				checked   func(a, b T) (T, error)
+				saturated func(a, b T) T
+				exact     *big.Int
+			}{
+				{"+", checkedAdd[T], saturatingAdd[T], new(big.Int).Add(x, y)},
+				{"-", checkedSub[T], saturatingSub[T], new(big.Int).Sub(x, y)},
+				{"*", checkedMul[T], saturatingMul[T], new(big.Int).Mul(x, y)},
+			} {
+				expr := fmt.Sprintf("%v %s %v", a, c.op, b)
+				r, err := c.checked(a, b)
+				checkResult(t, expr, c.exact, r, err)
+				checkSaturated(t, expr, c.exact, c.saturated(a, b))
+			}
+			expr := fmt.Sprintf("%v / %v", a, b)
+			r, err := checkedDiv(a, b)
+			if b == 0 {
+				if !errors.Is(err, errDivByZero) {
+					t.Errorf("%s (%T) = %v, %v, want errDivByZero", expr, a, r, err)
+				}
+				continue
+			}
+			checkResult(t, expr, new(big.Int).Quo(x, y), r, err) // Quo は Go の / と同じく 0 の方向に切り捨てる
+		}
+	}
+}
+
+func TestCheckedIntegerOps(t *testing.T) {
+	t.Run("int8", testIntegerOps[int8])
+	t.Run("int16", testIntegerOps[int16])
+	t.Run("int32", testIntegerOps[int32])
+	t.Run("int64", testIntegerOps[int64])
+	t.Run("int", testIntegerOps[int])
+	t.Run("uint8", testIntegerOps[uint8])
+	t.Run("uint16", testIntegerOps[uint16])
+	t.Run("uint32", testIntegerOps[uint32])
+	t.Run("uint64", testIntegerOps[uint64])
+	t.Run("uint", testIntegerOps[uint])
+}
+
+func TestBounds(t *testing.T) {
+	checkBounds := func(name string, gotMin, gotMax, wantMin, wantMax any) {
+		if gotMin != wantMin || gotMax != wantMax {
+			t.Errorf("bounds[%s]() = %v, %v, want %v, %v", name, gotMin, gotMax, wantMin, wantMax)
+		}
+	}
+	minI8, maxI8 := bounds[int8]()
+	checkBounds("int8", minI8, maxI8, int8(math.MinInt8), int8(math.MaxInt8))
+	minI64, maxI64 := bounds[int64]()
+	checkBounds("int64", minI64, maxI64, int64(math.MinInt64), int64(math.MaxInt64))
+	minU16, maxU16 := bounds[uint16]()
+	checkBounds("uint16", minU16, maxU16, uint16(0), uint16(math.MaxUint16))
+	minU, maxU := bounds[uint]()
+	checkBounds("uint", minU, maxU, uint(0), uint(math.MaxUint))
+}
+
+// 表せない結果 (最小値 / -1，-最小値 など) は，どの型でも折り返さずに桁あふれにする
+func TestCheckedMinimumEdgeCases(t *testing.T) {
+	cases := []struct {
+		expr string
+		eval func() error
+	}{
+		{"MinInt / -1", func() error { _, err := checkedDiv[int](math.MinInt, -1); return err }},
+		{"-MinInt", func() error { _, err := checkedNeg[int](math.MinInt); return err }},
+		{"MinInt * -1", func() error { _, err := checkedMul[int](math.MinInt, -1); return err }},
+		{"MinInt64 / -1", func() error { _, err := checkedDiv[int64](math.MinInt64, -1); return err }},
+		{"MinInt8 / -1", func() error { _, err := checkedDiv[int8](math.MinInt8, -1); return err }},
+		{"-MinInt8", func() error { _, err := checkedNeg[int8](math.MinInt8); return err }},
+		{"-uint(1)", func() error { _, err := checkedNeg[uint](1); return err }},
+	}
+	for _, c := range cases {
+		if err := c.eval(); !errors.Is(err, errOverflow) {
+			t.Errorf("%s: error = %v, want errOverflow", c.expr, err)
+		}
+	}
+}
+
+// strict モードの電卓は前置の - でも桁あふれを検出する
+func TestEvalStrictOverflow(t *testing.T) {
+	for _, input := range []string{
+		"-(-9223372036854775807 - 1)",
+		"(-9223372036854775807 - 1) / -1",
+		"9223372036854775807 + 1",
+		"-9223372036854775807 - 2",
+	} {
+		if got, err := evalStrict(input); !errors.Is(err, errOverflow) {
+			t.Errorf("evalStrict(%q) = %v, %v, want errOverflow", input, got, err)
+		}
+	}
+	if got, err := evalStrict("-(9223372036854775807)"); got != -math.MaxInt64 || err != nil {
+		t.Errorf("evalStrict(-(MaxInt64)) = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"learning-go/internal/catalog"
+	"learning-go/internal/fileio"
+)
+
+// 統計と同時に求めるチェックサム (sum コマンド，wc -sum)
+//   - 1回の読み込みで，統計 (exercise02_stats.go) と指定したダイジェストをまとめて求める
+//     - 読んだバイトを io.MultiWriter で統計とハッシュのすべてに配る
+//     - ダイジェストは sha256sum などと同じく，展開する前のファイルそのものから求める
+//       (統計は圧縮されたファイルなら展開した中身を数える。fileio.ReadFileTee)
+//   - ダイジェストの種類: crc32 (IEEE), md5, sha1, sha256
+//
+//   go run . sum [-a sha256] [ファイル...]
+//   - sha256sum と同じ形式 (「ダイジェスト  ファイル名」) で表示する
+//   - -a に2つ以上の種類を指定したら「SHA256 (ファイル名) = ダイジェスト」の形式 (--tag と同じ) で種類ごとに表示する
+//
+//   go run . sum -c [-a sha256] 一覧のファイル
+//   - sha256sum -c と同じく，一覧のファイルの各行のダイジェストを確かめて「ファイル名: OK」か「ファイル名: FAILED」を表示する
+//     - 一覧は上の2つの形式のどちらでもよい (「ダイジェスト *ファイル名」のバイナリの印も読み飛ばす)
+//     - 読めないファイルは「ファイル名: FAILED open or read」
+//   - 一致しないもの・読めないもの・形式の正しくない行があれば件数を警告し，終了ステータスを 1 にする
+//   - ファイル名に改行か \ を含む行は，先頭の \ と \n, \\ のエスケープで表す (sha256sum と同じ)
+//     (-c の「ファイル名: OK」などの表示も同じ)
+
+var digestAlgorithms = map[string]func() hash.Hash{
+	"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// --tag 形式の種類の名前
+var digestTags = map[string]string{
+	"crc32":  "CRC32",
+	"md5":    "MD5",
+	"sha1":   "SHA1",
+	"sha256": "SHA256",
+}
+
+// チェックサムを求めるときの1回の読み込みの大きさ
+const digestChunkSize = 32 * 1024
+
+type digest struct {
+	algorithm string
+	sum       []byte
+}
+
+// 「sha256,md5」のようなカンマ区切りの種類の一覧を読む
+func parseAlgorithms(s string) ([]string, error) {
+	var algorithms []string
+	for name := range strings.SplitSeq(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := digestAlgorithms[name]; !ok {
+			return nil, catalog.NewError(msgUnknownAlgorithm, name)
+		}
+		algorithms = append(algorithms, name)
+	}
+	return algorithms, nil
+}
+
+// 統計とダイジェストを 1 回の読み込みで求める
+type digestScanner struct {
+	counter statsCounter
+	hashes  []hash.Hash
+	raw     io.Writer // ハッシュすべてに配る
+}
+
+func newDigestScanner(algorithms []string) *digestScanner {
+	s := &digestScanner{}
+	writers := make([]io.Writer, len(algorithms))
+	for i, name := range algorithms {
+		h := digestAlgorithms[name]()
+		s.hashes = append(s.hashes, h)
+		writers[i] = h
+	}
+	s.raw = io.MultiWriter(writers...)
+	return s
+}
+
+func (s *digestScanner) count(chunk []byte) error {
+	s.counter.Write(chunk)
+	return nil
+}
+
+func (s *digestScanner) result(algorithms []string) (fileStats, []digest) {
+	digests := make([]digest, len(algorithms))
+	for i, name := range algorithms {
+		digests[i] = digest{name, s.hashes[i].Sum(nil)}
+	}
+	return s.counter.finish(), digests
+}
+
+// ファイルの統計とダイジェスト
+func scanFile(name string, algorithms []string) (fileStats, []digest, error) {
+	s := newDigestScanner(algorithms)
+	if err := fileio.ReadFileTee(name, digestChunkSize, s.raw, s.count); err != nil {
+		return fileStats{}, nil, err
+	}
+	stats, digests := s.result(algorithms)
+	return stats, digests, nil
+}
+
+// r (標準入力など) の統計とダイジェスト (展開はしない)
+func scanReader(r io.Reader, algorithms []string) (fileStats, []digest, error) {
+	s := newDigestScanner(algorithms)
+	w := io.MultiWriter(&s.counter, s.raw)
+	err := fileio.ReadChunks(r, digestChunkSize, func(chunk []byte) error {
+		_, err := w.Write(chunk)
+		return err
+	})
+	if err != nil {
+		return fileStats{}, nil, err
+	}
+	stats, digests := s.result(algorithms)
+	return stats, digests, nil
+}
+
+func scanPath(name string, algorithms []string) (fileStats, []digest, error) {
+	if name == "-" {
+		return scanReader(os.Stdin, algorithms)
+	}
+	return scanFile(name, algorithms)
+}
+
+// ファイル名の改行と \ をエスケープする (エスケープしたら true)
+func escapeFileName(name string) (string, bool) {
+	if !strings.ContainsAny(name, "\n\\") {
+		return name, false
+	}
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(name), true
+}
+
+func unescapeFileName(name string) string {
+	return strings.NewReplacer(`\\`, `\`, `\n`, "\n").Replace(name)
+}
+
+// 一覧の1行
+//   - sha256sum の形式: 「ダイジェスト  ファイル名」か「ダイジェスト *ファイル名」
+//   - --tag の形式: 「SHA256 (ファイル名) = ダイジェスト」
+var (
+	plainLinePattern = regexp.MustCompile(`^([0-9a-fA-F]+) [ *](.+)$`)
+	tagLinePattern   = regexp.MustCompile(`^([A-Z0-9]+) \((.+)\) = ([0-9a-fA-F]+)$`)
+)
+
+type manifestEntry struct {
+	algorithm string
+	name      string
+	sum       []byte
+}
+
+// 一覧の1行を読む (形式が正しくなければ false)
+func parseManifestLine(line, defaultAlgorithm string) (manifestEntry, bool) {
+	escaped := strings.HasPrefix(line, `\`)
+	if escaped {
+		line = line[1:]
+	}
+	var entry manifestEntry
+	var sum string
+	if m := tagLinePattern.FindStringSubmatch(line); m != nil {
+		for name, tag := range digestTags {
+			if tag == m[1] {
+				entry.algorithm = name
+			}
+		}
+		entry.name, sum = m[2], m[3]
+	} else if m := plainLinePattern.FindStringSubmatch(line); m != nil {
+		entry.algorithm, entry.name, sum = defaultAlgorithm, m[2], m[1]
+	}
+	newHash, ok := digestAlgorithms[entry.algorithm]
+	if !ok {
+		return manifestEntry{}, false
+	}
+	decoded, err := hex.DecodeString(sum)
+	if err != nil || len(decoded) != newHash().Size() {
+		return manifestEntry{}, false
+	}
+	entry.sum = decoded
+	if escaped {
+		entry.name = unescapeFileName(entry.name)
+	}
+	return entry, true
+}
+
+// 一覧を確かめた結果の件数
+type manifestCounts struct {
+	ok, mismatched, unreadable, badLines int
+}
+
+// 一致しないもの・読めないもの・形式の正しくない行が1つもないか
+func (c manifestCounts) passed() bool {
+	return c.mismatched == 0 && c.unreadable == 0 && c.badLines == 0
+}
+
+// 一覧のファイルを確かめる (結果は out に，読めなかった理由と件数の警告は errOut に書く)
+func checkManifest(manifest io.Reader, defaultAlgorithm string, out, errOut io.Writer) (manifestCounts, error) {
+	var counts manifestCounts
+	scanner := bufio.NewScanner(manifest)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entry, ok := parseManifestLine(line, defaultAlgorithm)
+		if !ok {
+			counts.badLines++
+			continue
+		}
+		display, escaped := escapeFileName(entry.name) // 改行を含む名前で表示が複数行にならないように書き戻す
+		if escaped {
+			display = `\` + display
+		}
+		_, digests, err := scanFile(entry.name, []string{entry.algorithm})
+		switch {
+		case err != nil:
+			fmt.Fprintln(errOut, "sum:", err)
+			fmt.Fprintf(out, "%s: FAILED open or read\n", display)
+			counts.unreadable++
+		case !bytes.Equal(digests[0].sum, entry.sum):
+			fmt.Fprintf(out, "%s: FAILED\n", display)
+			counts.mismatched++
+		default:
+			fmt.Fprintf(out, "%s: OK\n", display)
+			counts.ok++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return counts, err
+	}
+	if counts.badLines > 0 {
+		fmt.Fprintln(errOut, "sum:", catalog.Message(msgChecksumBadLines, counts.badLines))
+	}
+	if counts.unreadable > 0 {
+		fmt.Fprintln(errOut, "sum:", catalog.Message(msgChecksumUnreadable, counts.unreadable))
+	}
+	if counts.mismatched > 0 {
+		fmt.Fprintln(errOut, "sum:", catalog.Message(msgChecksumMismatch, counts.mismatched))
+	}
+	return counts, nil
+}
+
+func sumCommand(args []string) int {
+	fs := flag.NewFlagSet("sum", flag.ContinueOnError)
+	algorithmList := fs.String("a", "sha256", "ダイジェストの種類 (crc32, md5, sha1, sha256 をカンマで区切って指定する)")
+	check := fs.Bool("c", false, "一覧のファイルのダイジェストを確かめる (sha256sum -c と同じ)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	algorithms, err := parseAlgorithms(*algorithmList)
+	if err == nil && len(algorithms) == 0 {
+		err = catalog.NewError(msgUnknownAlgorithm, *algorithmList)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	names := fs.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	status := 0
+	if *check {
+		for _, name := range names {
+			var manifest io.Reader = os.Stdin
+			if name != "-" {
+				f, err := os.Open(name)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "sum:", err)
+					status = 1
+					continue
+				}
+				manifest = f
+			}
+			counts, err := checkManifest(manifest, algorithms[0], os.Stdout, os.Stderr) // エラーと順序がそろうように，バッファせずに書く
+			if f, isFile := manifest.(*os.File); isFile && f != os.Stdin {
+				f.Close()
+			}
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "sum:", err)
+			}
+			if !counts.passed() || err != nil {
+				status = 1
+			}
+		}
+		return status
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	for _, name := range names {
+		_, digests, err := scanPath(name, algorithms)
+		if err != nil {
+			out.Flush()
+			fmt.Fprintln(os.Stderr, "sum:", err)
+			status = 1
+			continue
+		}
+		display, escaped := escapeFileName(name)
+		prefix := ""
+		if escaped {
+			prefix = `\`
+		}
+		for _, d := range digests {
+			if len(digests) == 1 {
+				fmt.Fprintf(out, "%s%x  %s\n", prefix, d.sum, display)
+			} else {
+				fmt.Fprintf(out, "%s%s (%s) = %x\n", prefix, digestTags[d.algorithm], display, d.sum)
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func sha256Hex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParseManifestLine(t *testing.T) {
+	sha256Sum := sha256Hex("hello")
+	md5Sum := md5.Sum([]byte("hello"))
+	sha1Sum := sha1.Sum([]byte("hello"))
+	md5Hex, sha1Hex := hex.EncodeToString(md5Sum[:]), hex.EncodeToString(sha1Sum[:])
+	cases := []struct {
+		name      string
+		line      string
+		algorithm string // -a の種類 (sha256sum の形式の行で使う)
+		want      manifestEntry
+		ok        bool
+	}{
+		{"plain", sha256Sum + "  a.txt", "sha256", manifestEntry{"sha256", "a.txt", nil}, true},
+		{"binary marker", sha256Sum + " *a.txt", "sha256", manifestEntry{"sha256", "a.txt", nil}, true},
+		{"name with spaces", sha256Sum + "  a b.txt", "sha256", manifestEntry{"sha256", "a b.txt", nil}, true},
+		{"upper case hex", strings.ToUpper(sha256Sum) + "  a.txt", "sha256", manifestEntry{"sha256", "a.txt", nil}, true},
+		{"plain md5", md5Hex + "  a.txt", "md5", manifestEntry{"md5", "a.txt", nil}, true},
+		{"tag", "SHA1 (a (1).txt) = " + sha1Hex, "sha256", manifestEntry{"sha1", "a (1).txt", nil}, true},
+		{"tag md5", "MD5 (a.txt) = " + md5Hex, "sha256", manifestEntry{"md5", "a.txt", nil}, true},
+		{"escaped newline", `\` + sha256Sum + `  a\nb`, "sha256", manifestEntry{"sha256", "a\nb", nil}, true},
+		{"escaped backslash", `\` + sha256Sum + `  c\\d`, "sha256", manifestEntry{"sha256", `c\d`, nil}, true},
+		{"escaped tag", `\SHA256 (x\\n\n) = ` + sha256Sum, "sha256", manifestEntry{"sha256", "x\\n\n", nil}, true},
+		{"unescaped backslash", sha256Sum + `  c\\d`, "sha256", manifestEntry{"sha256", `c\\d`, nil}, true},
+		{"unknown tag", "BLAKE2 (a.txt) = " + sha256Sum, "sha256", manifestEntry{}, false},
+		{"tag with wrong length", "MD5 (a.txt) = " + sha256Sum, "sha256", manifestEntry{}, false},
+		{"plain with wrong length", md5Hex + "  a.txt", "sha256", manifestEntry{}, false},
+		{"odd length", sha256Sum[:63] + "  a.txt", "sha256", manifestEntry{}, false},
+		{"not hex", strings.Repeat("zz", 32) + "  a.txt", "sha256", manifestEntry{}, false},
+		{"no name", sha256Sum + "  ", "sha256", manifestEntry{}, false},
+		{"blank", "", "sha256", manifestEntry{}, false},
+		{"spaces only", "   ", "sha256", manifestEntry{}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := parseManifestLine(c.line, c.algorithm)
+			if ok != c.ok || got.algorithm != c.want.algorithm || got.name != c.want.name {
+				t.Errorf("parseManifestLine(%q) = %q, %q, %v, want %q, %q, %v",
+					c.line, got.algorithm, got.name, ok, c.want.algorithm, c.want.name, c.ok)
+			}
+			if ok && len(got.sum) != digestAlgorithms[got.algorithm]().Size() {
+				t.Errorf("parseManifestLine(%q): digest of %d bytes", c.line, len(got.sum))
+			}
+		})
+	}
+}
+
+// エスケープして戻すと元の名前になり，エスケープした名前は改行を含まない
+func TestEscapeFileName(t *testing.T) {
+	cases := []struct {
+		name    string
+		escaped string
+		changed bool
+	}{
+		{"a.txt", "a.txt", false},
+		{"a\nb", `a\nb`, true},
+		{`c\d`, `c\\d`, true},
+		{`\n`, `\\n`, true}, // \ と n の2文字 (改行ではない)
+		{"x\\\n", `x\\\n`, true},
+		{"\n\n", `\n\n`, true},
+	}
+	for _, c := range cases {
+		escaped, changed := escapeFileName(c.name)
+		if escaped != c.escaped || changed != c.changed {
+			t.Errorf("escapeFileName(%q) = %q, %v, want %q, %v", c.name, escaped, changed, c.escaped, c.changed)
+		}
+		if strings.Contains(escaped, "\n") {
+			t.Errorf("escapeFileName(%q) = %q contains a newline", c.name, escaped)
+		}
+		if back := unescapeFileName(escaped); back != c.name {
+			t.Errorf("unescapeFileName(%q) = %q, want %q", escaped, back, c.name)
+		}
+	}
+}
+
+func TestCheckManifest(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"a.txt": "hello", "b.txt": "world", "new\nline": "newline"}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+	escaped, _ := escapeFileName(path("new\nline"))
+	lines := []string{
+		sha256Hex("hello") + "  " + path("a.txt"),
+		"SHA256 (" + path("b.txt") + ") = " + sha256Hex("world"),
+		`\` + sha256Hex("newline") + "  " + escaped,
+		"",
+		sha256Hex("changed") + " *" + path("b.txt"),
+		sha256Hex("gone") + "  " + path("missing.txt"),
+		"not a checksum line",
+		"  ",
+		"MD5 (" + path("a.txt") + ") = " + sha256Hex("hello"),
+	}
+	cases := []struct {
+		name   string
+		lines  []string
+		want   manifestCounts
+		passed bool // 終了ステータスが 0 になるか
+		out    []string
+	}{
+		{"all ok", lines[:4], manifestCounts{ok: 3}, true, []string{
+			path("a.txt") + ": OK",
+			path("b.txt") + ": OK",
+			`\` + escaped + ": OK",
+		}},
+		{"failures", lines, manifestCounts{ok: 3, mismatched: 1, unreadable: 1, badLines: 2}, false, []string{
+			path("a.txt") + ": OK",
+			path("b.txt") + ": OK",
+			`\` + escaped + ": OK",
+			path("b.txt") + ": FAILED",
+			path("missing.txt") + ": FAILED open or read",
+		}},
+		{"empty", nil, manifestCounts{}, true, nil},
+		{"bad lines only", lines[6:], manifestCounts{badLines: 2}, false, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out, errOut strings.Builder
+			manifest := strings.Join(c.lines, "\r\n") // CRLF の一覧も読める
+			counts, err := checkManifest(strings.NewReader(manifest), "sha256", &out, &errOut)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if counts != c.want {
+				t.Errorf("counts = %+v, want %+v", counts, c.want)
+			}
+			if counts.passed() != c.passed {
+				t.Errorf("passed() = %v, want %v", counts.passed(), c.passed)
+			}
+			var got []string
+			if out.Len() > 0 {
+				got = strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			}
+			if strings.Join(got, "|") != strings.Join(c.out, "|") {
+				t.Errorf("output:\n%s\nwant:\n%s", out.String(), strings.Join(c.out, "\n"))
+			}
+			if warned := errOut.Len() > 0; warned == c.passed {
+				t.Errorf("warnings %q with counts %+v", errOut.String(), counts)
+			}
+		})
+	}
+}
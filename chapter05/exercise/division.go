@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math/big"
+
+	"learning-go/internal/catalog"
+	"learning-go/internal/division"
+)
+
+// div, mod と演算子 // (商と余りの定義は internal/division)
+//   - 電卓では div(a, b), mod(a, b) と演算子 // が選んだ定義に従う (/ は Go と同じ truncated のまま)
+//   - number モードと big モードでは整数同士のときだけ使える
+
+func parseDivisionMode(s string) (division.Mode, error) {
+	for _, mode := range division.Modes {
+		if s == mode.String() {
+			return mode, nil
+		}
+	}
+	return 0, catalog.NewError(msgUnknownDivision, s)
+}
+
+// mode の定義に従う商と余りの演算
+//   - check はそのモードの / の実装で，0 除算と桁あふれ (strict モードの最小値 / -1) を先に調べるのに使う
+func intDivision(mode division.Mode, check opFuncType) (quo, rem opFuncType) {
+	quo = func(a, b int) (int, error) {
+		if _, err := check(a, b); err != nil {
+			return 0, err
+		}
+		q, _ := division.QuoRem(a, b, mode)
+		return q, nil
+	}
+	rem = func(a, b int) (int, error) {
+		if b == 0 {
+			return 0, errDivByZero
+		}
+		_, r := division.QuoRem(a, b, mode)
+		return r, nil
+	}
+	return quo, rem
+}
+
+// number モードでは int 同士のときだけ使える
+func numberDivision(mode division.Mode) (quo, rem opFunc[number]) {
+	intOnly := func(f opFuncType) opFunc[number] {
+		return func(x, y number) (number, error) {
+			if x.kind != intNumber || y.kind != intNumber {
+				return number{}, catalog.ErrorOf(errType, msgTypeIntOnly)
+			}
+			r, err := f(x.i, y.i)
+			return intValue(r), err
+		}
+	}
+	intQuo, intRem := intDivision(mode, div)
+	return intOnly(intQuo), intOnly(intRem)
+}
+
+// big モードでは分母が 1 のとき (整数) だけ使える
+func bigDivision(mode division.Mode) (quo, rem opFunc[*big.Rat]) {
+	quoRem := func(x, y *big.Rat) (q, r *big.Int, err error) {
+		if !x.IsInt() || !y.IsInt() {
+			return nil, nil, catalog.ErrorOf(errType, msgTypeIntOnly)
+		}
+		b := y.Num()
+		if b.Sign() == 0 {
+			return nil, nil, errDivByZero
+		}
+		q, r = new(big.Int).QuoRem(x.Num(), b, new(big.Int))
+		if d := division.Adjust(r.Sign(), b.Sign(), mode); d != 0 {
+			q.Add(q, big.NewInt(int64(d)))
+			r.Sub(r, new(big.Int).Mul(big.NewInt(int64(d)), b))
+		}
+		return q, r, nil
+	}
+	quo = func(x, y *big.Rat) (*big.Rat, error) {
+		q, _, err := quoRem(x, y)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Rat).SetInt(q), nil
+	}
+	rem = func(x, y *big.Rat) (*big.Rat, error) {
+		_, r, err := quoRem(x, y)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Rat).SetInt(r), nil
+	}
+	return quo, rem
+}
+
+// 演算子 // と組み込み関数 div, mod を加えた arith[T] を返す (元の表は変わらない)
+func withDivision[T any](a arith[T], quo, rem opFunc[T]) arith[T] {
+	a = a.clone()
+	if err := registerOperator(&a, operatorDef[T]{Symbol: "//", Arity: 2, Prec: 2, Binary: quo}); err != nil {
+		panic(err) // 記号も優先順位も固定なので起きない
+	}
+	if a.funcs == nil {
+		a.funcs = map[string]builtin[T]{}
+	}
+	a.funcs["div"] = builtin[T]{2, func(args []T) (T, error) { return quo(args[0], args[1]) }}
+	a.funcs["mod"] = builtin[T]{2, func(args []T) (T, error) { return rem(args[0], args[1]) }}
+	return a
+}
+
+func intArithWithDivision(a arith[int], mode division.Mode) arith[int] {
+	quo, rem := intDivision(mode, a.ops["/"])
+	return withDivision(a, quo, rem)
+}
+
+func numberArithWithDivision(mode division.Mode) arith[number] {
+	quo, rem := numberDivision(mode)
+	return withDivision(numberArith, quo, rem)
+}
+
+func bigArithWithDivision(mode division.Mode) arith[*big.Rat] {
+	quo, rem := bigDivision(mode)
+	return withDivision(bigArith, quo, rem)
+}
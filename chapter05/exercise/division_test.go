@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"learning-go/internal/division"
+)
+
+// big モードの div, mod が int モードと同じ定義で計算するか
+func TestBigDivisionMatchesInt(t *testing.T) {
+	for _, mode := range division.Modes {
+		bigQuo, bigRem := bigDivision(mode)
+		for a := -9; a <= 9; a++ {
+			for b := -4; b <= 4; b++ {
+				x, y := big.NewRat(int64(a), 1), big.NewRat(int64(b), 1)
+				bq, errQ := bigQuo(x, y)
+				br, errR := bigRem(x, y)
+				if b == 0 {
+					if !errors.Is(errQ, errDivByZero) || !errors.Is(errR, errDivByZero) {
+						t.Errorf("%v: %d, 0: errors = %v, %v, want errDivByZero", mode, a, errQ, errR)
+					}
+					continue
+				}
+				q, r := division.QuoRem(a, b, mode)
+				if errQ != nil || errR != nil || bq.Cmp(big.NewRat(int64(q), 1)) != 0 || br.Cmp(big.NewRat(int64(r), 1)) != 0 {
+					t.Errorf("%v: %d, %d: big = %v, %v, int = %d, %d", mode, a, b, bq, br, q, r)
+				}
+				if a != q*b+r {
+					t.Errorf("%v: %d != %d * %d + %d", mode, a, q, b, r)
+				}
+			}
+		}
+		if _, err := bigQuo(big.NewRat(1, 2), big.NewRat(1, 1)); err == nil {
+			t.Errorf("%v: 1/2 // 1 should fail", mode)
+		}
+	}
+}
@@ -3,7 +3,11 @@ package main
 import (
 	"errors"
 	"fmt"
-	"strconv"
+	"math"
+	"math/big"
+
+	"learning-go/internal/catalog"
+	"learning-go/internal/division"
 )
 
 var (
@@ -12,58 +16,285 @@ var (
 	mul = func(i int, j int) (int, error) { return i * j, nil }
 	div = func(i int, j int) (int, error) {
 		if j == 0 {
-			return 0, errors.New("0で割ることはできません")
+			return 0, errDivByZero
 		}
 		return i / j, nil
 	}
 )
 
+type opFuncType = opFunc[int]
+
+var opMap = map[string]opFuncType{
+	"+": add,
+	"-": sub,
+	"*": mul,
+	"/": div,
+}
+
+// strict モードの演算子表: 桁あふれを折り返さずにエラーにする
+var strictOpMap = map[string]opFuncType{
+	"+": checkedAdd[int],
+	"-": checkedSub[int],
+	"*": checkedMul[int],
+	"/": checkedDiv[int],
+}
+
 func exercise01() {
-	type opFuncType func(int, int) (int, error)
-	var opMap = map[string]opFuncType{
-		"+": add,
-		"-": sub,
-		"*": mul,
-		"/": div,
-	}
-
-	expressions := [][]string{
-		[]string{"2", "+", "3"},
-		[]string{"2", "-", "3"},
-		[]string{"2", "*", "3"},
-		[]string{"2", "/", "3"},
-		[]string{"2", "%", "3"},
-		[]string{"two", "+", "three"},
-		[]string{"2", "+", "three"},
-		[]string{"5"},
-		[]string{"2", "/", "0"},
+	expressions := []string{
+		"2 + 3",
+		"2 - 3",
+		"2 * 3",
+		"2 / 3",
+		"2 % 3",
+		"two + three",
+		"2 + three",
+		"5",
+		"2 / 0",
+		"(2 + 3) * -4 / 2",
+		"8 - 3 - 2",
+		"2 + 3 * 4",
+		"(2 + 3",
+		"2 +",
+		"2 * (3 + 4)) - 1",
+		"(2 + 3) * 4 / (1 - 1)",
+		"10 / 2 # コメント",
+		"１ + 2",
 	}
 	for _, expression := range expressions {
-		if len(expression) != 3 {
-			fmt.Print(expression, " -- 不正な式です\n")
+		result, err := eval(expression)
+		if err != nil {
+			fmt.Print(expression, " -- ", err, "\n")
+			var exprErr *exprError
+			if errors.As(err, &exprErr) {
+				fmt.Println(expression)
+				fmt.Println(exprErr.caret())
+			}
+			switch {
+			case errors.Is(err, errDivByZero):
+				fmt.Println("  (ゼロ除算)")
+			case errors.Is(err, errOverflow):
+				fmt.Println("  (桁あふれ)")
+			case errors.Is(err, errUndefined):
+				fmt.Println("  (未定義の名前)")
+			case errors.Is(err, errUnknownOp):
+				fmt.Println("  (未定義の演算子)")
+			case errors.Is(err, errSyntax):
+				fmt.Println("  (構文エラー)")
+			}
 			continue
 		}
-		p1, err := strconv.Atoi(expression[0])
+		fmt.Print(expression, " → ", result, "\n")
+	}
+
+	// 任意精度モード: 同じ式を *big.Rat で評価する
+	bigExpressions := []string{
+		"2 / 3",
+		"2 / 3 + 1 / 6",
+		"9223372036854775807 + 1",
+		"9223372036854775807 * 9223372036854775807",
+		"(1 - 1 / 3) * 3",
+		"2 / (3 - 3)",
+	}
+	for _, expression := range bigExpressions {
+		intResult, intErr := eval(expression)
+		bigResult, err := evalBig(expression)
 		if err != nil {
 			fmt.Print(expression, " -- ", err, "\n")
 			continue
 		}
-		op := expression[1]
-		opFunc, ok := opMap[op]
-		if !ok {
-			fmt.Print(expression, " -- ", "定義されていない演算子です: ", op, "\n")
+		if intErr != nil {
+			fmt.Print(expression, " → ", bigResult.RatString(), " (int: ", intErr, ")\n")
+			continue
+		}
+		fmt.Print(expression, " → ", bigResult.RatString(), " (int: ", intResult, ")\n")
+	}
+
+	// strict モード: 桁あふれをエラーにする
+	strictExpressions := []string{
+		"9223372036854775807 - 1",
+		"9223372036854775807 + 1",
+		"-9223372036854775807 - 2",
+		"3037000500 * 3037000500",
+		"(-9223372036854775807 - 1) / -1",
+	}
+	for _, expression := range strictExpressions {
+		result, err := evalStrict(expression)
+		if err != nil {
+			fmt.Print(expression, " -- ", err, "\n")
 			continue
 		}
-		p2, err := strconv.Atoi(expression[2])
+		fmt.Print(expression, " → ", result, "\n")
+	}
+
+	// 浮動小数点数・複素数モード: 2章の float64 / complex128 の計算を電卓で行う
+	numberExpressions := []string{
+		"2 / 3",
+		"2.0 / 3",
+		"2.5e10 + 1",
+		"1_000.000_1 * 2",
+		"0x2A + 1e-5",
+		"3+4i",
+		"(3+4i) * 2.5",
+		"complex(2.5, 3.1) * complex(10.2, 2)",
+		"abs(3+4i)",
+		"abs(-2)",
+		"real(3+4i) + imag(3+4i)",
+		"sqrt(2)",
+		"sqrt(-4 + 0i)",
+		"pow(2, 10)",
+		"pow(1i, 2)",
+		"1 / 0.0",
+		"1 / 0",
+		"abs(1, 2)",
+		"foo(1)",
+		"1.2.3",
+		"(x -> x * 2)(21)",
+		"(b -> x -> b * x)(2.5)(4)",
+		"x -> x",
+	}
+	for _, expression := range numberExpressions {
+		result, err := evalNumber(expression)
 		if err != nil {
 			fmt.Print(expression, " -- ", err, "\n")
 			continue
 		}
-		result, err := opFunc(p1, p2)
+		fmt.Print(expression, " → ", result, "\n")
+	}
+
+	// 演算子の登録: int モードを複製して %, **, <<, &, 前置の ~ を追加する
+	//   - 優先順位は Go に合わせる (% << & は * / と同じ，** はそれより強い右結合)
+	bitArith := intArith.clone()
+	for _, def := range []operatorDef[int]{
+		{Symbol: "%", Arity: 2, Prec: 2, Binary: func(i, j int) (int, error) {
+			if j == 0 {
+				return 0, errDivByZero
+			}
+			return i % j, nil
+		}},
+		{Symbol: "**", Arity: 2, Prec: 3, Assoc: rightAssoc, Binary: func(i, j int) (int, error) {
+			if j < 0 {
+				return 0, catalog.NewError(msgNegativeExponent)
+			}
+			result := 1
+			for ; j > 0; j-- {
+				result *= i
+			}
+			return result, nil
+		}},
+		{Symbol: "<<", Arity: 2, Prec: 2, Binary: func(i, j int) (int, error) {
+			if j < 0 {
+				return 0, catalog.NewError(msgNegativeShift)
+			}
+			return i << j, nil
+		}},
+		{Symbol: "&", Arity: 2, Prec: 2, Binary: func(i, j int) (int, error) { return i & j, nil }},
+		{Symbol: "~", Arity: 1, Unary: func(i int) (int, error) { return ^i, nil }},
+	} {
+		if err := registerOperator(&bitArith, def); err != nil {
+			fmt.Println(err)
+		}
+	}
+	err := registerOperator(&bitArith, operatorDef[int]{Symbol: "(", Arity: 2, Prec: 1, Binary: add})
+	fmt.Println(err) // 演算子を登録できません: '(' は記号に使えません
+	err = registerOperator(&bitArith, operatorDef[int]{Symbol: "==", Arity: 2, Prec: 1, Binary: sub})
+	fmt.Println(err) // 演算子を登録できません: = で始まる記号は let と def の = と紛らわしいので使えません
+	registeredExpressions := []string{
+		"7 % 3",
+		"2 ** 3 ** 2",
+		"2 * 3 ** 2",
+		"1 << 4 + 1",
+		"12 & 10",
+		"~5 & 255",
+		"2 % 0",
+	}
+	for _, expression := range registeredExpressions {
+		result, err := evalWith(bitArith, expression)
 		if err != nil {
 			fmt.Print(expression, " -- ", err, "\n")
 			continue
 		}
 		fmt.Print(expression, " → ", result, "\n")
 	}
+	_, err = eval("7 % 3") // 元の int モードには影響しない
+	fmt.Println(err)       // 3文字目: 定義されていない演算子です: %
+
+	// 除算の定義を選ぶ: div, mod, // は選んだ定義に従い，/ は Go と同じまま
+	for _, mode := range division.Modes {
+		a := intArithWithDivision(intArith, mode)
+		q, _ := evalWith(a, "-7 // 2")
+		r, _ := evalWith(a, "mod(-7, -2)")
+		t, _ := evalWith(a, "-7 / 2")
+		fmt.Println(mode, q, r, t) // truncated -3 -1 -3 / floored -4 -1 -3 / euclidean -4 1 -3
+	}
+
+	// Go の整数リテラル・ルーンリテラル (2章 main.go) と，基数を指定した表示
+	for _, expression := range []string{"0b1010 + 0o644 + 0x2A + 1_000_000", "'a' + '\\x61'", "0xF0 & ~0b1010_0000"} {
+		result, err := evalWith(bitArith, expression)
+		if err != nil {
+			fmt.Println(expression, "--", err)
+			continue
+		}
+		x := big.NewInt(int64(result))
+		fmt.Println(expression, "→", result, formatInteger(x, outputFormat{16, 4}), formatInteger(x, outputFormat{2, 4}))
+		// 1000472 0xf_4418 0b1111_0100_0100_0001_1000 / 194 0xc2 0b1100_0010 / 80 0x50 0b101_0000
+	}
+
+	// 簡約と整形: 保存する前に式を決まった形にそろえる
+	for _, expression := range []string{
+		"((x*1)) + 0*y - (2+3)*4",
+		"1 - (2 - 3) - x",
+		"def area(w,h)=(w*h)/1",
+		"price / (10 - 10)",
+	} {
+		normalized, problems, err := normalize(numberArith, expression)
+		if err != nil {
+			fmt.Println(expression, "--", err)
+			continue
+		}
+		fmt.Println(expression, "→", normalized) // x - 20 / 2 - x / def area(w, h) = w * h / price / 0
+		for _, p := range problems {
+			fmt.Println("  ", p) // 7文字目: 0で割ることはできません
+		}
+	}
+
+	// バイトコード: 一度コンパイルした式を変数の値を変えて何度も実行する
+	prog, err := compile(intArith, "price * count - price * count / 10")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(prog)
+	m := prog.machine()
+	for _, row := range []map[string]int{
+		{"price": 100, "count": 3},
+		{"price": 250, "count": 4},
+		{"price": 80},
+	} {
+		vars, err := prog.bind(row)
+		if err != nil {
+			fmt.Println(row, "--", err) // 定義されていない名前です: count
+			continue
+		}
+		result, err := m.run(vars)
+		if err != nil {
+			fmt.Println(row, "--", err)
+			continue
+		}
+		fmt.Println(row, "→", result)
+	}
+	_, err = compile(intArith, "(x -> x * 2)(21)")
+	fmt.Println(err) // 2文字目: バイトコードにできません: 組み込み関数以外は呼び出せません
+
+	// int 以外の整数型でも使える (2章 exercise03 の byte, int32, int64)
+	var b byte = math.MaxUint8
+	var smallI int32 = math.MaxInt32
+	var bigI int64 = math.MaxInt64
+	_, errB := checkedAdd(b, 1)
+	_, errSmallI := checkedAdd(smallI, 1)
+	_, errBigI := checkedAdd(bigI, 1)
+	fmt.Println(errB)                                                                  // 桁あふれしました: 255 + 1 (uint8)
+	fmt.Println(errSmallI)                                                             // 桁あふれしました: 2147483647 + 1 (int32)
+	fmt.Println(errBigI)                                                               // 桁あふれしました: 9223372036854775807 + 1 (int64)
+	fmt.Println(saturatingAdd(b, 1), saturatingAdd(smallI, 1), saturatingAdd(bigI, 1)) // 255 2147483647 9223372036854775807
+	fmt.Println(saturatingSub[uint](1, 2), saturatingMul[int8](-100, 2))               // 0 -128
 }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"learning-go/internal/catalog"
+)
+
+// 電卓のバッチ処理 (batch コマンド)
+//   go run . batch [-mode number|int|strict|big] [-division truncated|floored|euclidean] [-format jsonl|csv] [ファイル]
+//   - ファイル (省略時や「-」なら標準入力) から1行に1つずつ式を読んで評価する
+//     - 空行は読み飛ばす。let 文・def 文も使え，後の行から参照できる
+//   - 結果を他のツールで読める形式で標準出力に書く
+//     - jsonl: 1行に1つの JSON オブジェクト (JSON Lines)
+//     - csv:   見出し行 line,input,result,error_kind,error に続けて1行に1レコード
+//   - 1行でも失敗すれば終了ステータスは 1
+
+// 1行分の結果
+//   - 結果は数値のモードによって複素数や分数になるので，文字列で持つ
+//   - ErrorKind は errorKind の名前 (成功したら空)
+type batchRecord struct {
+	Line      int    `json:"line"`
+	Input     string `json:"input"`
+	Result    string `json:"result"`
+	ErrorKind string `json:"error_kind"`
+	Error     string `json:"error"`
+}
+
+type recordWriter interface {
+	write(r batchRecord) error
+	flush() error
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func newJSONLWriter(w io.Writer) *jsonlWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false) // 「<」「&」などの演算子を \u003c のようにエスケープしない
+	return &jsonlWriter{enc}
+}
+
+func (w *jsonlWriter) write(r batchRecord) error {
+	return w.enc.Encode(r)
+}
+
+func (w *jsonlWriter) flush() error {
+	return nil
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool // 見出し行を書いたか
+}
+
+func (w *csvWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	return w.w.Write([]string{"line", "input", "result", "error_kind", "error"})
+}
+
+func (w *csvWriter) write(r batchRecord) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	return w.w.Write([]string{strconv.Itoa(r.Line), r.Input, r.Result, r.ErrorKind, r.Error})
+}
+
+// 入力が空でも見出し行は書く
+func (w *csvWriter) flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func batchCommand(args []string) int {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	mode := fs.String("mode", "number", "評価モード (number, int, strict, big)")
+	divisionFlag := fs.String("division", "truncated", "div, mod, // の定義 (truncated, floored, euclidean)")
+	format := fs.String("format", "jsonl", "出力形式 (jsonl, csv)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	division, err := parseDivisionMode(*divisionFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if fs.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, catalog.Message(msgOneFile))
+		return 2
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	var w recordWriter
+	switch *format {
+	case "jsonl":
+		w = newJSONLWriter(out)
+	case "csv":
+		w = &csvWriter{w: csv.NewWriter(out)}
+	default:
+		fmt.Fprintln(os.Stderr, catalog.Message(msgUnknownFormat, *format))
+		return 2
+	}
+
+	var in io.Reader = os.Stdin
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var failed int
+	switch *mode {
+	case "number":
+		failed, err = batch(&evaluator[number]{arith: numberArithWithDivision(division)}, in, w)
+	case "int":
+		failed, err = batch(&evaluator[int]{arith: intArithWithDivision(intArith, division)}, in, w)
+	case "strict":
+		failed, err = batch(&evaluator[int]{arith: intArithWithDivision(strictArith, division)}, in, w)
+	case "big":
+		failed, err = batch(&evaluator[*big.Rat]{arith: bigArithWithDivision(division)}, in, w)
+	default:
+		fmt.Fprintln(os.Stderr, catalog.Message(msgUnknownMode, *mode))
+		return 2
+	}
+	if err == nil {
+		err = w.flush()
+	}
+	if flushErr := out.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// in の各行を評価して w に書き，失敗した行の数を返す
+//   - 返すエラーは読み書きの失敗だけで，式の評価の失敗はレコードに入れる
+func batch[T any](e *evaluator[T], in io.Reader, w recordWriter) (failed int, err error) {
+	scanner := bufio.NewScanner(in)
+	line := 0
+	for scanner.Scan() {
+		line++
+		input := scanner.Text()
+		if strings.TrimSpace(input) == "" {
+			continue
+		}
+		r := batchRecord{Line: line, Input: input}
+		_, v, err := e.run(input)
+		if err != nil {
+			failed++
+			r.ErrorKind = errorKind(err)
+			r.Error = err.Error()
+		} else {
+			r.Result = e.format(v)
+		}
+		if err := w.write(r); err != nil {
+			return failed, err
+		}
+	}
+	return failed, scanner.Err()
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+
+	"learning-go/internal/catalog"
+)
+
+// 評価方法ごとの速さの比較
+//   go test -run '^$' -bench Eval -benchmem .
+//   - 同じ式を変数 x, y の値を変えながら評価する (表の1行ごとに同じ式を計算する場面を想定)
+//     - tokens:  元の exercise01 と同じく，[]string のトークンを毎回 strconv.Atoi で変換する
+//                (「数 演算子 数」の3つのトークンの式しか扱えないので，simple の式だけで比べる)
+//     - reparse: 今の exercise01 の eval と同じく，毎回字句解析・構文解析・数値リテラルの変換をする
+//     - tree:    構文解析は一度だけにして，構文木をたどって評価する
+//     - vm:      一度だけバイトコードにコンパイルし，スタックマシンで実行する
+
+var benchExprs = []struct {
+	name string
+	expr string
+}{
+	{"simple", "x * y"},
+	{"long", "(x + 3) * (y - 2) / 4 + x * y - 10 * (x - y)"},
+}
+
+// 表の行 (x, y の値の組)
+type benchRow struct{ x, y int }
+
+func benchRows() []benchRow {
+	rows := make([]benchRow, 700)
+	for i := range rows {
+		rows[i] = benchRow{i % 100, i % 7}
+	}
+	return rows
+}
+
+// 元の exercise01 の評価 (「数 演算子 数」のトークン列)
+func evalTokens(expression []string) (int, error) {
+	if len(expression) != 3 {
+		return 0, errSyntax
+	}
+	p1, err := strconv.Atoi(expression[0])
+	if err != nil {
+		return 0, err
+	}
+	opFunc, ok := opMap[expression[1]]
+	if !ok {
+		return 0, catalog.ErrorOf(errUnknownOp, msgUnknownOpSymbol, expression[1])
+	}
+	p2, err := strconv.Atoi(expression[2])
+	if err != nil {
+		return 0, err
+	}
+	return opFunc(p1, p2)
+}
+
+// 行の値を埋め込んだトークン列 (表のデータを文字列のまま持っている場面)
+func tokenRows(rows []benchRow) [][]string {
+	tokens := make([][]string, len(rows))
+	for i, row := range rows {
+		tokens[i] = []string{strconv.Itoa(row.x), "*", strconv.Itoa(row.y)}
+	}
+	return tokens
+}
+
+// 評価の方法 (行ごとに x, y を束縛して評価する関数を作る)
+type evalMethod func(tb testing.TB, expr string) func(row benchRow) (int, error)
+
+var evalMethods = []struct {
+	name   string
+	method evalMethod
+}{
+	{"reparse", func(tb testing.TB, expr string) func(benchRow) (int, error) {
+		e := &evaluator[int]{arith: intArith}
+		vars := e.global().vars
+		return func(row benchRow) (int, error) {
+			vars["x"], vars["y"] = value[int]{num: row.x}, value[int]{num: row.y}
+			_, v, err := e.run(expr)
+			return v.num, err
+		}
+	}},
+	{"tree", func(tb testing.TB, expr string) func(benchRow) (int, error) {
+		tree, err := parse(expr, intArith.syntax)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		e := &evaluator[int]{arith: intArith}
+		vars := e.global().vars
+		return func(row benchRow) (int, error) {
+			vars["x"], vars["y"] = value[int]{num: row.x}, value[int]{num: row.y}
+			v, err := e.evalSource(tree, expr)
+			return v.num, err
+		}
+	}},
+	{"vm", func(tb testing.TB, expr string) func(benchRow) (int, error) {
+		prog, err := compile(intArith, expr)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		m := prog.machine()
+		slots := make([]int, len(prog.vars))
+		xSlot, ySlot := prog.slot("x"), prog.slot("y")
+		return func(row benchRow) (int, error) {
+			if xSlot >= 0 {
+				slots[xSlot] = row.x
+			}
+			if ySlot >= 0 {
+				slots[ySlot] = row.y
+			}
+			return m.run(slots)
+		}
+	}},
+}
+
+// 比べる前に，どの方法でも同じ結果になることを確かめる
+func TestEvalMethodsAgree(t *testing.T) {
+	rows := benchRows()
+	tokens := tokenRows(rows)
+	for _, be := range benchExprs {
+		evals := make([]func(benchRow) (int, error), len(evalMethods))
+		for i, m := range evalMethods {
+			evals[i] = m.method(t, be.expr)
+		}
+		for i, row := range rows {
+			want, wantErr := evals[0](row)
+			for j, eval := range evals[1:] {
+				got, err := eval(row)
+				if (err == nil) != (wantErr == nil) || got != want {
+					t.Fatalf("%s %v: %s = %d (%v), %s = %d (%v)", be.expr, row,
+						evalMethods[j+1].name, got, err, evalMethods[0].name, want, wantErr)
+				}
+			}
+			if be.name == "simple" {
+				if got, err := evalTokens(tokens[i]); err != nil || got != want {
+					t.Fatalf("%v: tokens = %d (%v), want %d", tokens[i], got, err, want)
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkEval(b *testing.B) {
+	rows := benchRows()
+	tokens := tokenRows(rows)
+	for _, be := range benchExprs {
+		b.Run(be.name, func(b *testing.B) {
+			if be.name == "simple" {
+				b.Run("tokens", func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; b.Loop(); i++ {
+						evalTokens(tokens[i%len(tokens)])
+					}
+				})
+			}
+			for _, m := range evalMethods {
+				b.Run(m.name, func(b *testing.B) {
+					eval := m.method(b, be.expr)
+					b.ReportAllocs()
+					for i := 0; b.Loop(); i++ {
+						eval(rows[i%len(rows)])
+					}
+				})
+			}
+		})
+	}
+}
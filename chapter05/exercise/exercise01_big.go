@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math/big"
+	"strings"
+
+	"learning-go/internal/catalog"
+)
+
+// 任意精度モード
+//   - int モードでは math.MaxInt64 + 1 が黙って負の数になってしまう (2章 exercise03 を参照)
+//   - big モードでは値を *big.Rat (分母が 1 なら整数) で持ち，桁あふれも切り捨てもしない
+//     - 「2 / 3」は 0 ではなく 2/3 になる
+//   - 演算子表の形は int モードの opMap と同じ (記号 → 関数)
+//   - *big.Rat のメソッドはレシーバを書き換えるので，演算のたびに new(big.Rat) で結果を作る
+
+var (
+	bigAdd = func(i, j *big.Rat) (*big.Rat, error) { return new(big.Rat).Add(i, j), nil }
+	bigSub = func(i, j *big.Rat) (*big.Rat, error) { return new(big.Rat).Sub(i, j), nil }
+	bigMul = func(i, j *big.Rat) (*big.Rat, error) { return new(big.Rat).Mul(i, j), nil }
+	bigDiv = func(i, j *big.Rat) (*big.Rat, error) {
+		if j.Sign() == 0 {
+			return nil, errDivByZero
+		}
+		return new(big.Rat).Quo(i, j), nil
+	}
+)
+
+var bigOpMap = map[string]opFunc[*big.Rat]{
+	"+": bigAdd,
+	"-": bigSub,
+	"*": bigMul,
+	"/": bigDiv,
+}
+
+// 整数リテラルを *big.Int で読み，*big.Rat にする (桁数の上限はない)
+//   - 0x2A や 1_000 など Go の整数リテラルの形も読む (基数 0 を指定すると接頭辞から基数を決める)
+func parseBigLiteral(s string) (*big.Rat, error) {
+	if strings.HasPrefix(s, "'") {
+		r, err := parseRuneLiteral(s)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Rat).SetInt64(int64(r)), nil
+	}
+	i, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, catalog.NewError(msgBigLiteral, s)
+	}
+	return new(big.Rat).SetInt(i), nil
+}
+
+var bigArith = arith[*big.Rat]{
+	syntax:  defaultOperators(),
+	ops:     bigOpMap,
+	literal: parseBigLiteral,
+	format:  (*big.Rat).RatString,
+	integer: func(r *big.Rat) (*big.Int, bool) { return r.Num(), r.IsInt() },
+	zero:    new(big.Rat),
+}
+
+// 文字列の式を任意精度で評価する
+func evalBig(input string) (*big.Rat, error) {
+	return evalWith(bigArith, input)
+}
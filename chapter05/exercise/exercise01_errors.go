@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"learning-go/internal/catalog"
+)
+
+// 電卓のエラー
+//   - エラーの種類はセンチネルエラーで表し，errors.Is で区別する
+//     - errSyntax: 式の構文が正しくない (数値として読めない語も含む)
+//     - errUnknownOp: 定義されていない演算子
+//     - errDivByZero: 0 による除算
+//     - errUndefined: 定義されていない名前 (関数など)
+//     - errArgs: 関数の引数の数や種類が合わない
+//     - errType: 関数を数値として計算しようとした，数値を関数として呼び出そうとした
+//     - errDepth: 関数呼び出しが深すぎる (終わらない再帰など)
+//     - errTimeout: 制限時間内に計算が終わらなかった (HTTP サーバなどで評価を打ち切ったとき)
+//   - 入力中のどこで起きたかは *exprError に入れて返し，errors.As で取り出す
+
+var (
+	errSyntax    = catalog.NewError(msgSyntax)
+	errUnknownOp = catalog.NewError(msgUnknownOp)
+	errDivByZero = catalog.NewError(msgDivByZero)
+	errUndefined = catalog.NewError(msgUndefined)
+	errArgs      = catalog.NewError(msgArgs)
+	errType      = catalog.NewError(msgType)
+	errDepth     = catalog.NewError(msgDepth)
+	errTimeout   = catalog.NewError(msgTimeout)
+)
+
+type exprError struct {
+	input string // 式全体 (キャレット表示に使う)
+	pos   int    // エラーのあったトークンのバイト位置
+	err   error
+}
+
+func (e *exprError) Error() string {
+	if e.input == "" {
+		return e.err.Error()
+	}
+	return catalog.Message(msgAt, e.runeOffset()+1, e.err)
+}
+
+func (e *exprError) Unwrap() error {
+	return e.err
+}
+
+// エラー位置のバイトオフセット
+func (e *exprError) offset() int {
+	return e.pos
+}
+
+// エラー位置のルーンオフセット (先頭から何文字目か，0始まり)
+func (e *exprError) runeOffset() int {
+	return utf8.RuneCountInString(e.prefix())
+}
+
+// 入力のうちエラー位置より前の部分
+func (e *exprError) prefix() string {
+	return e.input[:min(e.pos, len(e.input))]
+}
+
+// エラー位置の下に ^ を置いた行を返す
+//   - 入力の直下に表示することを想定している
+//   - 全角文字は2桁，タブはタブのまま数えて位置を揃える
+func (e *exprError) caret() string {
+	var b strings.Builder
+	for _, r := range e.prefix() {
+		switch {
+		case r == '\t':
+			b.WriteRune('\t')
+		case isWideRune(r):
+			b.WriteString("  ")
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	b.WriteRune('^')
+	return b.String()
+}
+
+// 端末上で2桁分の幅を取る文字 (おおよその判定)
+func isWideRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		(r >= 0x3000 && r <= 0x303F) || // CJKの記号と句読点
+		(r >= 0xFF01 && r <= 0xFF60) || // 全角英数・記号
+		(r >= 0xFFE0 && r <= 0xFFE6)
+}
+
+// 位置付きのエラーを作る (input は parse / eval が最後に埋める)
+func errorAt(pos int, err error) error {
+	return &exprError{pos: pos, err: err}
+}
+
+// 位置付きのエラーに入力全体を結びつける (すでに結びついていればそのまま)
+func withInput(err error, input string) error {
+	var e *exprError
+	if errors.As(err, &e) && e.input == "" {
+		e.input = input
+	}
+	return err
+}
+
+// エラーの種類を表す短い名前 (batch コマンドなど，他のツールに渡す出力で使う)
+//   - 関数の中で起きたエラーは中のエラーの種類になる
+func errorKind(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, errTimeout):
+		return "timeout"
+	case errors.Is(err, errDepth):
+		return "depth"
+	case errors.Is(err, errOverflow):
+		return "overflow"
+	case errors.Is(err, errDivByZero):
+		return "div_by_zero"
+	case errors.Is(err, errUndefined):
+		return "undefined"
+	case errors.Is(err, errUnknownOp):
+		return "unknown_op"
+	case errors.Is(err, errArgs):
+		return "args"
+	case errors.Is(err, errType):
+		return "type"
+	case errors.Is(err, errNotCompilable):
+		return "not_compilable"
+	case errors.Is(err, errSyntax):
+		return "syntax"
+	}
+	return "other"
+}
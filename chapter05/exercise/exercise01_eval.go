@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"learning-go/internal/catalog"
+)
+
+// 構文木の評価
+//   - 評価モードごとに「値の型 T」「演算子表」「関数表」「数値リテラルの変換」を arith[T] にまとめる
+//   - 構文木をたどる処理 evaluator[T] はどのモードでも共通 (ジェネリクスを利用)
+//   - int モードの演算子表は exercise01 の opMap そのもの
+//   - 評価中の値は数値 (T) か関数 (closure) のどちらか
+//     - 関数は定義された時点の環境 (scope) を持つクロージャで，外側の変数を参照できる (example007 の makeMult と同じ)
+
+type opFunc[T any] func(T, T) (T, error)
+
+// 組み込み関数
+type builtin[T any] struct {
+	arity int // 引数の数
+	fn    func(args []T) (T, error)
+}
+
+type arith[T any] struct {
+	syntax  *operatorTable
+	ops     map[string]opFunc[T]          // 二項演算子
+	prefix  map[string]func(T) (T, error) // 前置の単項演算子 (+ と - は登録がなければ 0 + x, 0 - x とする)
+	funcs   map[string]builtin[T]         // 組み込み関数 (ないモードでは nil)
+	literal func(string) (T, error)       // 数値リテラルを値に変換する
+	format  func(T) string                // 値を表示用の文字列にする
+	text    func(T) string                // 値を読み直しても同じ値になる数値リテラルにする (nil なら format と同じ)
+	integer func(T) (*big.Int, bool)      // 値が整数ならその値 (基数を指定した表示に使う)
+	zero    T                             // 単項演算子の左辺 (-x は 0 - x として評価する)
+	kinds   bool                          // 値に種類がある (number の int / float64 / complex128。0 * x の種類が x で変わる)
+}
+
+var intArith = arith[int]{
+	syntax:  defaultOperators(),
+	ops:     opMap,
+	literal: parseIntLiteral,
+	format:  strconv.Itoa,
+	integer: intInteger,
+}
+
+var strictArith = arith[int]{
+	syntax:  defaultOperators(),
+	ops:     strictOpMap,
+	prefix:  map[string]func(int) (int, error){"-": checkedNeg[int]},
+	literal: parseIntLiteral,
+	format:  strconv.Itoa,
+	integer: intInteger,
+}
+
+func intInteger(i int) (*big.Int, bool) {
+	return big.NewInt(int64(i)), true
+}
+
+// 評価中の値
+type value[T any] struct {
+	num T
+	fn  *closure[T] // 関数なら nil 以外
+}
+
+type closure[T any] struct {
+	name   string // def で定義した関数の名前 (無名関数なら "")
+	params []string
+	body   node
+	env    *scope[T] // 関数が定義された時点の環境
+	source string    // 関数を定義した入力 (本体の中で起きたエラーの表示に使う)
+}
+
+func (c *closure[T]) String() string {
+	return catalog.Message(msgFunction, c.name, strings.Join(c.params, ", "))
+}
+
+// 変数の環境 (関数呼び出しのたびに，関数が定義された環境を親にして作る)
+type scope[T any] struct {
+	vars   map[string]value[T]
+	parent *scope[T]
+}
+
+func newScope[T any](parent *scope[T]) *scope[T] {
+	return &scope[T]{vars: map[string]value[T]{}, parent: parent}
+}
+
+func (s *scope[T]) lookup(name string) (value[T], bool) {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v, true
+		}
+	}
+	return value[T]{}, false
+}
+
+// 関数呼び出しの深さの上限 (終わらない再帰でスタックを使い切らないようにする)
+const maxCallDepth = 1000
+
+// 評価器: 評価モードとグローバルな変数の組
+//   - 変数は let / def で定義する (REPL を参照)
+type evaluator[T any] struct {
+	arith   arith[T]
+	globals *scope[T]
+	depth   int
+	source  string          // 評価中の構文木の元になった入力
+	ctx     context.Context // 打ち切られたら関数呼び出しのたびに errTimeout を返す (nil なら打ち切らない)
+}
+
+func (e *evaluator[T]) global() *scope[T] {
+	if e.globals == nil {
+		e.globals = newScope[T](nil)
+	}
+	return e.globals
+}
+
+func (e *evaluator[T]) format(v value[T]) string {
+	if v.fn != nil {
+		return v.fn.String()
+	}
+	return e.arith.format(v.num)
+}
+
+// 整数の値を基数と桁区切りを指定して表示する (整数でない値はいつもの表示)
+func (e *evaluator[T]) formatIn(v value[T], f outputFormat) string {
+	if v.fn == nil && e.arith.integer != nil && (f.base != 10 || f.group > 0) {
+		if i, ok := e.arith.integer(v.num); ok {
+			return formatInteger(i, f)
+		}
+	}
+	return e.format(v)
+}
+
+// 1行分の入力 (式または let 文・def 文) を解析し，グローバルな環境で評価する
+//   - 解析した構文木も返す (REPL が let 文かどうかを見分けるのに使う)
+func (e *evaluator[T]) run(input string) (node, value[T], error) {
+	n, err := parseStatement(input, e.arith.syntax)
+	if err != nil {
+		return nil, value[T]{}, err
+	}
+	v, err := e.evalSource(n, input)
+	return n, v, err
+}
+
+// input を解析した構文木 n をグローバルな環境で評価する
+func (e *evaluator[T]) evalSource(n node, input string) (value[T], error) {
+	e.source = input
+	v, err := e.evalIn(n, e.global())
+	if err != nil {
+		return v, withInput(err, input)
+	}
+	return v, nil
+}
+
+func (e *evaluator[T]) evalIn(n node, env *scope[T]) (value[T], error) {
+	var zero value[T]
+	switch n := n.(type) {
+	case numberNode:
+		v, err := e.arith.literal(n.text)
+		if err != nil {
+			return zero, errorAt(n.pos, catalog.ErrorOf(errSyntax, msgSyntaxCause, err))
+		}
+		return value[T]{num: v}, nil
+	case unaryNode:
+		v, err := e.numIn(n.operand, env)
+		if err != nil {
+			return zero, err
+		}
+		if f, ok := e.arith.prefix[n.op]; ok {
+			result, err := f(v)
+			if err != nil {
+				return zero, errorAt(n.pos, err)
+			}
+			return value[T]{num: result}, nil
+		}
+		return e.apply(n.pos, n.op, e.arith.zero, v)
+	case binaryNode:
+		l, err := e.numIn(n.left, env)
+		if err != nil {
+			return zero, err
+		}
+		r, err := e.numIn(n.right, env)
+		if err != nil {
+			return zero, err
+		}
+		return e.apply(n.pos, n.op, l, r)
+	case identNode:
+		v, ok := env.lookup(n.name)
+		if !ok {
+			return zero, errorAt(n.pos, catalog.ErrorOf(errUndefined, msgUndefinedName, n.name))
+		}
+		return v, nil
+	case lambdaNode:
+		return value[T]{fn: &closure[T]{n.name, n.params, n.body, env, e.source}}, nil
+	case callNode:
+		return e.call(n, env)
+	case letNode:
+		v, err := e.evalIn(n.value, env)
+		if err != nil {
+			return zero, err
+		}
+		env.vars[n.name] = v
+		return v, nil
+	}
+	panic(fmt.Sprintf("未知のノードです: %T", n))
+}
+
+// 式を評価し，数値でなければエラーにする (演算子や組み込み関数の引数に使う)
+func (e *evaluator[T]) numIn(n node, env *scope[T]) (T, error) {
+	v, err := e.evalIn(n, env)
+	if err == nil && v.fn != nil {
+		err = errorAt(n.position(), catalog.ErrorOf(errType, msgTypeFuncInArith))
+	}
+	return v.num, err
+}
+
+func (e *evaluator[T]) apply(pos int, op string, l, r T) (value[T], error) {
+	opFunc, ok := e.arith.ops[op]
+	if !ok {
+		return value[T]{}, errorAt(pos, catalog.ErrorOf(errUnknownOp, msgUnknownOpSymbol, op))
+	}
+	result, err := opFunc(l, r)
+	if err != nil {
+		return value[T]{}, errorAt(pos, err)
+	}
+	return value[T]{num: result}, nil
+}
+
+// 関数呼び出し
+//   - 名前で呼び出す場合は変数 (let / def で定義した関数) を先に探し，なければ組み込み関数を探す
+//   - 引数は呼び出し側の環境で評価し，本体は関数が定義された環境の子で評価する
+//   - 本体の中のエラー位置は関数を定義した入力でのものなので，その入力を結びつけてから
+//     呼び出し位置のエラーで包む (深すぎる再帰は包まずにそのまま返す)
+func (e *evaluator[T]) call(n callNode, env *scope[T]) (value[T], error) {
+	var zero value[T]
+	var fn value[T]
+	if ident, ok := n.fn.(identNode); ok {
+		v, found := env.lookup(ident.name)
+		if !found {
+			return e.callBuiltin(ident, n.args, env)
+		}
+		fn = v
+	} else {
+		v, err := e.evalIn(n.fn, env)
+		if err != nil {
+			return zero, err
+		}
+		fn = v
+	}
+	c := fn.fn
+	if c == nil {
+		return zero, errorAt(n.pos, catalog.ErrorOf(errType, msgTypeNotCallable))
+	}
+	if len(n.args) != len(c.params) {
+		return zero, errorAt(n.pos, catalog.ErrorOf(errArgs, msgArgsCount, c, len(c.params)))
+	}
+	local := newScope(c.env)
+	for i, arg := range n.args {
+		v, err := e.evalIn(arg, env)
+		if err != nil {
+			return zero, err
+		}
+		local.vars[c.params[i]] = v
+	}
+	if e.depth >= maxCallDepth {
+		return zero, errorAt(n.pos, errDepth)
+	}
+	if e.ctx != nil && e.ctx.Err() != nil { // 時間のかかる計算は関数呼び出し (再帰) からしか生まれない
+		return zero, errorAt(n.pos, errTimeout)
+	}
+	e.depth++
+	caller := e.source
+	e.source = c.source
+	v, err := e.evalIn(c.body, local)
+	e.source = caller
+	e.depth--
+	if err != nil {
+		err = withInput(err, c.source)
+		if errors.Is(err, errDepth) || errors.Is(err, errTimeout) {
+			return zero, err
+		}
+		return zero, errorAt(n.pos, catalog.NewError(msgInside, c, err))
+	}
+	return v, nil
+}
+
+func (e *evaluator[T]) callBuiltin(ident identNode, argNodes []node, env *scope[T]) (value[T], error) {
+	var zero value[T]
+	f, ok := e.arith.funcs[ident.name]
+	if !ok {
+		return zero, errorAt(ident.pos, catalog.ErrorOf(errUndefined, msgUndefinedName, ident.name))
+	}
+	if len(argNodes) != f.arity {
+		return zero, errorAt(ident.pos, catalog.ErrorOf(errArgs, msgArgsCount, ident.name, f.arity))
+	}
+	args := make([]T, 0, len(argNodes))
+	for _, arg := range argNodes {
+		v, err := e.numIn(arg, env)
+		if err != nil {
+			return zero, err
+		}
+		args = append(args, v)
+	}
+	v, err := f.fn(args)
+	if err != nil {
+		return zero, errorAt(ident.pos, err)
+	}
+	return value[T]{num: v}, nil
+}
+
+// 文字列の式を解析し，指定したモードで評価する
+func evalWith[T any](a arith[T], input string) (T, error) {
+	n, err := parse(input, a.syntax)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	e := &evaluator[T]{arith: a}
+	v, err := e.evalSource(n, input)
+	if err == nil && v.fn != nil {
+		err = withInput(errorAt(n.position(), catalog.ErrorOf(errType, msgTypeFuncResult)), input)
+	}
+	return v.num, err
+}
+
+// 文字列の式を int で評価する
+func eval(input string) (int, error) {
+	return evalWith(intArith, input)
+}
+
+// 文字列の式を int で評価し，桁あふれをエラーにする
+func evalStrict(input string) (int, error) {
+	return evalWith(strictArith, input)
+}
@@ -0,0 +1,108 @@
+package main
+
+import "strings"
+
+// 構文木の整形
+//   - 構文木を決まった形の文字列に戻す (「(2+3)*4」も「( 2 + 3 ) * 4」も「(2 + 3) * 4」になる)
+//     - 二項演算子の前後に空白を1つ，関数呼び出しの引数はカンマと空白で区切る
+//     - 括弧は意味を変えないために必要なところにだけ付ける
+//       (子の二項演算子の優先順位が親より低いとき，同じなら結合性と逆の側にあるとき)
+//   - 整形した文字列をもう一度解析すると同じ構文木になる
+//   - def 文は let f = (...) -> ... ではなく def f(...) = ... の形に戻す
+
+func formatNode(n node, ops *operatorTable) string {
+	var b strings.Builder
+	f := &formatter{ops: ops, b: &b}
+	f.node(n)
+	return b.String()
+}
+
+type formatter struct {
+	ops *operatorTable
+	b   *strings.Builder
+}
+
+func (f *formatter) node(n node) {
+	switch n := n.(type) {
+	case numberNode:
+		f.b.WriteString(n.text)
+	case identNode:
+		f.b.WriteString(n.name)
+	case unaryNode:
+		f.b.WriteString(n.op)
+		// 「- -x」を「--x」と書くと別の演算子として読まれることがある
+		if _, ok := n.operand.(unaryNode); ok {
+			f.b.WriteByte(' ')
+		}
+		_, binary := n.operand.(binaryNode)
+		_, lambda := n.operand.(lambdaNode)
+		f.operand(n.operand, binary || lambda)
+	case binaryNode:
+		syntax := f.ops.binary[n.op]
+		f.operand(n.left, f.needsParens(n.left, syntax, leftAssoc))
+		f.b.WriteString(" " + n.op + " ")
+		f.operand(n.right, f.needsParens(n.right, syntax, rightAssoc))
+	case callNode:
+		f.operand(n.fn, !isAtom(n.fn))
+		f.b.WriteByte('(')
+		for i, arg := range n.args {
+			if i > 0 {
+				f.b.WriteString(", ")
+			}
+			f.node(arg)
+		}
+		f.b.WriteByte(')')
+	case lambdaNode:
+		if len(n.params) == 1 {
+			f.b.WriteString(n.params[0])
+		} else {
+			f.b.WriteString("(" + strings.Join(n.params, ", ") + ")")
+		}
+		f.b.WriteString(" -> ")
+		f.node(n.body)
+	case letNode:
+		if fn, ok := n.value.(lambdaNode); ok && fn.name == n.name {
+			f.b.WriteString("def " + n.name + "(" + strings.Join(fn.params, ", ") + ") = ")
+			f.node(fn.body)
+			return
+		}
+		f.b.WriteString("let " + n.name + " = ")
+		f.node(n.value)
+	}
+}
+
+func (f *formatter) operand(n node, parens bool) {
+	if parens {
+		f.b.WriteByte('(')
+	}
+	f.node(n)
+	if parens {
+		f.b.WriteByte(')')
+	}
+}
+
+// 括弧なしで関数呼び出しの対象にできるノード
+func isAtom(n node) bool {
+	switch n.(type) {
+	case numberNode, identNode, callNode:
+		return true
+	}
+	return false
+}
+
+// 二項演算子の side 側 (左か右) にある子 n に括弧が要るか
+//   - 無名関数は本体が右端まで続くので，二項演算子の中では常に括弧が要る
+//   - 左結合の演算子では右側の，右結合の演算子では左側の同じ優先順位の演算子に括弧が要る
+func (f *formatter) needsParens(n node, parent opSyntax, side associativity) bool {
+	switch n := n.(type) {
+	case lambdaNode:
+		return true
+	case binaryNode:
+		child := f.ops.binary[n.op]
+		if child.prec != parent.prec {
+			return child.prec < parent.prec
+		}
+		return parent.assoc != side
+	}
+	return false
+}
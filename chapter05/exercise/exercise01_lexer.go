@@ -0,0 +1,149 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"learning-go/internal/catalog"
+)
+
+// 字句解析 (トークナイザ)
+//   - 入力文字列を数値・識別子・演算子・括弧などのトークン列に分割する
+//   - 空白は読み飛ばす
+//   - 数値は Go の数値リテラルの形 (2.5e10, 1_000.000_1, 4i など) をひとまとまりで読む
+//     - ルーンリテラル ('a', '\x61' など) も数値として読む
+//     - 正しいリテラルかどうかは評価モードごとの変換関数が判断する
+//   - 「->」は無名関数の矢印として読む (演算子としては登録できない)
+//   - 「$」に数字が続くもの ($1 など) は識別子として読む (REPL の結果参照)
+//   - 演算子は登録された記号のうち最も長く一致するものを取り，なければ1文字で区切る
+//     (「*-4」は「*」と「-」と「4」に，「**」が登録されていれば「2**3」は「2」「**」「3」になる)
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota // 数値リテラル
+	tokenIdent                   // 識別子 (関数名など)
+	tokenOp                      // 演算子
+	tokenLParen                  // (
+	tokenRParen                  // )
+	tokenComma                   // ,
+	tokenArrow                   // -> (無名関数)
+	tokenEOF                     // 入力の終わり
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 入力中のバイト位置
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isDigit(b byte) bool {
+	return '0' <= b && b <= '9'
+}
+
+// 数値リテラルの終わりの位置を返す
+//   - 英数字・「_」・「.」に加え，指数部の直後の符号 (1e-5, 0x1p-2) も含める
+//   - 16進数では e は桁なので，符号を許すのは p の直後だけ
+func scanNumber(input string, start int) int {
+	hex := len(input) >= start+2 && input[start] == '0' && (input[start+1] == 'x' || input[start+1] == 'X')
+	pos := start
+	for pos < len(input) {
+		c := input[pos]
+		switch {
+		case c == '_' || c == '.' || isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			pos++
+		case (c == '+' || c == '-') && pos > start && isExponent(input[pos-1], hex):
+			pos++
+		default:
+			return pos
+		}
+	}
+	return pos
+}
+
+// ルーンリテラルの終わり (閉じる「'」の次) の位置を返す (閉じていなければ -1)
+//   - 「\'」や「\\」はエスケープなので読み飛ばす
+func scanRune(input string, start int) int {
+	for pos := start + 1; pos < len(input); pos++ {
+		switch input[pos] {
+		case '\\':
+			pos++
+		case '\'':
+			return pos + 1
+		}
+	}
+	return -1
+}
+
+func isExponent(c byte, hex bool) bool {
+	if hex {
+		return c == 'p' || c == 'P'
+	}
+	return c == 'e' || c == 'E'
+}
+
+func tokenize(input string, ops *operatorTable) ([]token, error) {
+	var tokens []token
+	for pos := 0; pos < len(input); {
+		r, size := utf8.DecodeRuneInString(input[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case isDigit(input[pos]) || (r == '.' && pos+1 < len(input) && isDigit(input[pos+1])):
+			start := pos
+			pos = scanNumber(input, pos)
+			tokens = append(tokens, token{tokenNumber, input[start:pos], start})
+		case r == '\'':
+			end := scanRune(input, pos)
+			if end < 0 {
+				return nil, errorAt(pos, catalog.ErrorOf(errSyntax, msgSyntaxRune))
+			}
+			tokens = append(tokens, token{tokenNumber, input[pos:end], pos})
+			pos = end
+		case r == '_' || unicode.IsLetter(r):
+			start := pos
+			for pos < len(input) {
+				r, size := utf8.DecodeRuneInString(input[pos:])
+				if !isWordRune(r) {
+					break
+				}
+				pos += size
+			}
+			tokens = append(tokens, token{tokenIdent, input[start:pos], start})
+		case r == '$' && pos+1 < len(input) && isDigit(input[pos+1]):
+			start := pos
+			pos++
+			for pos < len(input) && isDigit(input[pos]) {
+				pos++
+			}
+			tokens = append(tokens, token{tokenIdent, input[start:pos], start})
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", pos})
+			pos += size
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", pos})
+			pos += size
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", pos})
+			pos += size
+		case r == '-' && pos+1 < len(input) && input[pos+1] == '>':
+			tokens = append(tokens, token{tokenArrow, "->", pos})
+			pos += 2
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol := ops.longestMatch(input[pos:])
+			if symbol == "" {
+				symbol = string(r)
+			}
+			tokens = append(tokens, token{tokenOp, symbol, pos})
+			pos += len(symbol)
+		default:
+			return nil, errorAt(pos, catalog.ErrorOf(errSyntax, msgSyntaxBadChar, r))
+		}
+	}
+	tokens = append(tokens, token{tokenEOF, "", len(input)})
+	return tokens, nil
+}
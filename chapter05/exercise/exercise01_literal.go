@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// 整数リテラルと基数を指定した表示
+//   - 2章の main.go で見た Go の整数リテラルをすべて読む
+//     - 0b1010 (2進数), 0o644 と 0644 (8進数), 42 (10進数), 0x2A (16進数), 1_000_000 (_ で区切る)
+//     - ルーンリテラル 'a', '\141', '\x61', 'a', '\U00000061', '\n' は文字のコードポイントの整数
+//   - 結果は 16進数・2進数・8進数でも表示でき，_ で桁を区切れる (REPL の :hex, :bin, :oct, :dec)
+//     - 表示は Go のリテラルの形 (0x2a, -0b1010, 0xff_ff) なので，そのまま入力に使える
+
+// ルーンリテラル ('a' など) の値
+func parseRuneLiteral(s string) (rune, error) {
+	if len(s) < 3 || s[0] != '\'' || s[len(s)-1] != '\'' {
+		return 0, strconv.ErrSyntax
+	}
+	r, _, tail, err := strconv.UnquoteChar(s[1:len(s)-1], '\'')
+	if err != nil {
+		return 0, err
+	}
+	if tail != "" { // 'ab' のように2文字以上ある
+		return 0, strconv.ErrSyntax
+	}
+	return r, nil
+}
+
+// Go の整数リテラル・ルーンリテラルを int として読む (int モードと strict モード)
+func parseIntLiteral(s string) (int, error) {
+	if strings.HasPrefix(s, "'") {
+		r, err := parseRuneLiteral(s)
+		return int(r), err
+	}
+	i, err := strconv.ParseInt(s, 0, 0)
+	return int(i), err
+}
+
+// 表示の基数と桁区切り
+type outputFormat struct {
+	base  int // 2, 8, 10, 16
+	group int // 何桁ごとに _ で区切るか (0 なら区切らない)
+}
+
+var basePrefixes = map[int]string{2: "0b", 8: "0o", 10: "", 16: "0x"}
+
+// 整数 x を f の基数と桁区切りで表す
+func formatInteger(x *big.Int, f outputFormat) string {
+	digits := new(big.Int).Abs(x).Text(f.base)
+	if f.group > 0 {
+		var b strings.Builder
+		for i, d := range digits {
+			if i > 0 && (len(digits)-i)%f.group == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(d)
+		}
+		digits = b.String()
+	}
+	sign := ""
+	if x.Sign() < 0 {
+		sign = "-"
+	}
+	return sign + basePrefixes[f.base] + digits
+}
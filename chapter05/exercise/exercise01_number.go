@@ -0,0 +1,219 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+	"strconv"
+	"strings"
+
+	"learning-go/internal/catalog"
+)
+
+// 浮動小数点数・複素数モード
+//   - 値は int, float64, complex128 のいずれかで，number 型にまとめて持つ
+//   - 二項演算では2つの値を広い方の種類にそろえてから計算する (int → float64 → complex128)
+//     - Go 自体は暗黙の型変換をしないが，型なし定数の式 (2 + 2.5 や 3 + 4i) と同じように振る舞わせる
+//   - int 同士の演算は int モードと同じ add/sub/mul/div を使う (2 / 3 は 0)
+//   - 浮動小数点数・複素数を 0 で割るとエラーではなく +Inf / -Inf / NaN になる (2章を参照)
+//   - 組み込み関数 abs, real, imag, sqrt, pow, complex は math と math/cmplx で計算する
+
+type numberKind int
+
+const (
+	intNumber numberKind = iota
+	floatNumber
+	complexNumber
+)
+
+type number struct {
+	kind numberKind
+	i    int
+	f    float64
+	c    complex128
+}
+
+func intValue(i int) number            { return number{kind: intNumber, i: i} }
+func floatValue(f float64) number      { return number{kind: floatNumber, f: f} }
+func complexValue(c complex128) number { return number{kind: complexNumber, c: c} }
+
+func (x number) isComplex() bool {
+	return x.kind == complexNumber
+}
+
+// x を種類 k まで広げる (すでに k 以上ならそのまま)
+func (x number) widen(k numberKind) number {
+	if x.kind >= k {
+		return x
+	}
+	if k == floatNumber {
+		return floatValue(x.float())
+	}
+	return complexValue(x.complex())
+}
+
+func (x number) float() float64 {
+	switch x.kind {
+	case intNumber:
+		return float64(x.i)
+	case floatNumber:
+		return x.f
+	}
+	return real(x.c)
+}
+
+func (x number) complex() complex128 {
+	if x.kind == complexNumber {
+		return x.c
+	}
+	return complex(x.float(), 0)
+}
+
+func (x number) String() string {
+	switch x.kind {
+	case intNumber:
+		return strconv.Itoa(x.i)
+	case floatNumber:
+		return strconv.FormatFloat(x.f, 'g', -1, 64)
+	}
+	return fmt.Sprint(x.c) // (3+4i) の形
+}
+
+// 読み直しても同じ種類の値になる数値リテラル (float64 の 3 は「3」ではなく「3.0」)
+func (x number) text() string {
+	s := x.String()
+	if x.kind == floatNumber && !strings.ContainsAny(s, ".eEnN") { // 指数・NaN・Inf の表示はそのまま
+		s += ".0"
+	}
+	return s
+}
+
+// 2つの値を広い方の種類にそろえる
+func promote(x, y number) (number, number) {
+	k := max(x.kind, y.kind)
+	return x.widen(k), y.widen(k)
+}
+
+type floatOrComplex interface {
+	~float64 | ~complex128
+}
+
+func fcAdd[T floatOrComplex](a, b T) T { return a + b }
+func fcSub[T floatOrComplex](a, b T) T { return a - b }
+func fcMul[T floatOrComplex](a, b T) T { return a * b }
+func fcDiv[T floatOrComplex](a, b T) T { return a / b }
+
+// 種類ごとの演算を1つの演算子にまとめる
+func numberOp(intOp opFuncType, floatOp func(float64, float64) float64, complexOp func(complex128, complex128) complex128) opFunc[number] {
+	return func(x, y number) (number, error) {
+		x, y = promote(x, y)
+		switch x.kind {
+		case intNumber:
+			r, err := intOp(x.i, y.i)
+			return intValue(r), err
+		case floatNumber:
+			return floatValue(floatOp(x.f, y.f)), nil
+		}
+		return complexValue(complexOp(x.c, y.c)), nil
+	}
+}
+
+var numberOpMap = map[string]opFunc[number]{
+	"+": numberOp(add, fcAdd[float64], fcAdd[complex128]),
+	"-": numberOp(sub, fcSub[float64], fcSub[complex128]),
+	"*": numberOp(mul, fcMul[float64], fcMul[complex128]),
+	"/": numberOp(div, fcDiv[float64], fcDiv[complex128]),
+}
+
+var numberFuncs = map[string]builtin[number]{
+	"abs": {1, func(args []number) (number, error) {
+		x := args[0]
+		switch x.kind {
+		case intNumber:
+			if x.i < 0 {
+				return intValue(-x.i), nil
+			}
+			return x, nil
+		case floatNumber:
+			return floatValue(math.Abs(x.f)), nil
+		}
+		return floatValue(cmplx.Abs(x.c)), nil
+	}},
+	"real": {1, func(args []number) (number, error) {
+		return floatValue(args[0].float()), nil
+	}},
+	"imag": {1, func(args []number) (number, error) {
+		return floatValue(imag(args[0].complex())), nil
+	}},
+	// 負の実数の平方根は math.Sqrt と同じく NaN になる (複素数で渡せば sqrt(-4 + 0i) == 2i)
+	"sqrt": {1, func(args []number) (number, error) {
+		x := args[0]
+		if x.isComplex() {
+			return complexValue(cmplx.Sqrt(x.c)), nil
+		}
+		return floatValue(math.Sqrt(x.float())), nil
+	}},
+	"pow": {2, func(args []number) (number, error) {
+		x, y := promote(args[0], args[1])
+		if x.isComplex() {
+			return complexValue(cmplx.Pow(x.c, y.c)), nil
+		}
+		return floatValue(math.Pow(x.float(), y.float())), nil
+	}},
+	"complex": {2, func(args []number) (number, error) {
+		re, im := args[0], args[1]
+		if re.isComplex() || im.isComplex() {
+			return number{}, catalog.ErrorOf(errArgs, msgArgsComplex)
+		}
+		return complexValue(complex(re.float(), im.float())), nil
+	}},
+}
+
+// Go の数値リテラルを読む
+//   - 末尾が i なら虚数 (4i → 0+4i)
+//   - 整数として読めれば int (0x2A や 1_000_000 も可)，そうでなければ float64 (2.5e10, 1_000.000_1)
+//   - ルーンリテラル ('a' など) は int
+func parseNumberLiteral(s string) (number, error) {
+	if strings.HasPrefix(s, "'") {
+		r, err := parseRuneLiteral(s)
+		return intValue(int(r)), err
+	}
+	if imagPart, ok := strings.CutSuffix(s, "i"); ok {
+		f, err := strconv.ParseFloat(imagPart, 64)
+		if err != nil {
+			return number{}, err
+		}
+		return complexValue(complex(0, f)), nil
+	}
+	i, err := strconv.ParseInt(s, 0, 0)
+	if err == nil {
+		return intValue(int(i)), nil
+	}
+	if errors.Is(err, strconv.ErrRange) { // 整数の形だが int に収まらない
+		return number{}, err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return number{}, err
+	}
+	return floatValue(f), nil
+}
+
+var numberArith = arith[number]{
+	syntax:  defaultOperators(),
+	ops:     numberOpMap,
+	funcs:   numberFuncs,
+	literal: parseNumberLiteral,
+	format:  number.String,
+	text:    number.text,
+	integer: func(x number) (*big.Int, bool) { return big.NewInt(int64(x.i)), x.kind == intNumber },
+	zero:    intValue(0),
+	kinds:   true,
+}
+
+// 文字列の式を int / float64 / complex128 で評価する
+func evalNumber(input string) (number, error) {
+	return evalWith(numberArith, input)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"maps"
+	"strings"
+	"unicode"
+
+	"learning-go/internal/catalog"
+)
+
+// 演算子の登録
+//   - 演算子の構文 (優先順位・結合性・前置かどうか) は operatorTable に，実装は arith[T] の表に持つ
+//   - registerOperator で記号 (%, **, <<, & など) を追加すると，字句解析・構文解析・評価のすべてが拾う
+//     - 2文字以上の記号は字句解析で最長一致させる (「**」は「*」「*」ではなく「**」)
+//   - 「->」(無名関数) と「=」(let と def) で始まる記号は構文と紛らわしいので登録できない
+//     (「<=」のように途中に = を含むものは登録できる)
+//   - 評価モードごとに別の演算子を持てるよう，clone で arith[T] を複製してから登録する
+//     (intArith などに直接登録すると，同じ表を共有する全員に影響する)
+
+type associativity int
+
+const (
+	leftAssoc  associativity = iota // 左結合: a - b - c == (a - b) - c
+	rightAssoc                      // 右結合: a ** b ** c == a ** (b ** c)
+)
+
+func (a associativity) String() string {
+	if a == rightAssoc {
+		return "右結合"
+	}
+	return "左結合"
+}
+
+// 二項演算子の構文
+type opSyntax struct {
+	prec  int // 優先順位 (大きいほど強く結びつく)
+	assoc associativity
+}
+
+type operatorTable struct {
+	binary map[string]opSyntax
+	prefix map[string]bool // 前置の単項演算子 (どの二項演算子よりも強く結びつく)
+}
+
+// 組み込みの演算子 (+ - * / と，前置の + -)
+func defaultOperators() *operatorTable {
+	return &operatorTable{
+		binary: map[string]opSyntax{
+			"+": {1, leftAssoc},
+			"-": {1, leftAssoc},
+			"*": {2, leftAssoc},
+			"/": {2, leftAssoc},
+		},
+		prefix: map[string]bool{
+			"+": true,
+			"-": true,
+		},
+	}
+}
+
+func (t *operatorTable) clone() *operatorTable {
+	return &operatorTable{
+		binary: maps.Clone(t.binary),
+		prefix: maps.Clone(t.prefix),
+	}
+}
+
+// s の先頭に一致する最も長い演算子を返す (なければ "")
+func (t *operatorTable) longestMatch(s string) string {
+	longest := ""
+	match := func(symbol string) {
+		if len(symbol) > len(longest) && strings.HasPrefix(s, symbol) {
+			longest = symbol
+		}
+	}
+	for symbol := range t.binary {
+		match(symbol)
+	}
+	for symbol := range t.prefix {
+		match(symbol)
+	}
+	return longest
+}
+
+// 登録する演算子の定義
+//   - Arity が 1 なら前置の単項演算子で Unary を，2 なら二項演算子で Binary を実装に使う
+//   - 単項演算子の Prec と Assoc は使わない
+type operatorDef[T any] struct {
+	Symbol string
+	Arity  int
+	Prec   int
+	Assoc  associativity
+	Unary  func(T) (T, error)
+	Binary opFunc[T]
+}
+
+var errBadOperator = catalog.NewError(msgBadOperator)
+
+// 演算子の記号に使える文字 (括弧・カンマ・$ は他のトークンと紛らわしいので使えない)
+func isOperatorRune(r rune) bool {
+	switch r {
+	case '(', ')', ',', '$', '_':
+		return false
+	}
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+func registerOperator[T any](a *arith[T], def operatorDef[T]) error {
+	if def.Symbol == "" {
+		return catalog.ErrorOf(errBadOperator, msgBadOperatorEmpty)
+	}
+	if strings.HasPrefix(def.Symbol, "->") {
+		return catalog.ErrorOf(errBadOperator, msgBadOperatorArrow)
+	}
+	if strings.HasPrefix(def.Symbol, "=") { // 「==」などを登録すると「let x == 1」の = を読めなくなる
+		return catalog.ErrorOf(errBadOperator, msgBadOperatorEquals)
+	}
+	for _, r := range def.Symbol {
+		if !isOperatorRune(r) {
+			return catalog.ErrorOf(errBadOperator, msgBadOperatorRune, r)
+		}
+	}
+	switch def.Arity {
+	case 1:
+		if def.Unary == nil {
+			return catalog.ErrorOf(errBadOperator, msgBadOperatorImpl, def.Symbol, "Unary")
+		}
+		if a.prefix == nil {
+			a.prefix = map[string]func(T) (T, error){}
+		}
+		a.prefix[def.Symbol] = def.Unary
+		a.syntax.prefix[def.Symbol] = true
+	case 2:
+		if def.Binary == nil {
+			return catalog.ErrorOf(errBadOperator, msgBadOperatorImpl, def.Symbol, "Binary")
+		}
+		if def.Prec < 1 {
+			return catalog.ErrorOf(errBadOperator, msgBadOperatorPrec, def.Symbol)
+		}
+		a.ops[def.Symbol] = def.Binary
+		a.syntax.binary[def.Symbol] = opSyntax{def.Prec, def.Assoc}
+	default:
+		return catalog.ErrorOf(errBadOperator, msgBadOperatorArity, def.Symbol)
+	}
+	return nil
+}
+
+// 演算子の表を複製した arith[T] を返す (複製に登録しても元の表は変わらない)
+func (a arith[T]) clone() arith[T] {
+	a.ops = maps.Clone(a.ops)
+	a.prefix = maps.Clone(a.prefix)
+	a.funcs = maps.Clone(a.funcs)
+	a.syntax = a.syntax.clone()
+	return a
+}
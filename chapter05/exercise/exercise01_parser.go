@@ -0,0 +1,391 @@
+package main
+
+import (
+	"strings"
+
+	"learning-go/internal/catalog"
+)
+
+// 構文解析 (優先順位法による再帰下降パーサ)
+//   stmt    = "let" ident "=" expr                       (let 文と def 文は parseStatement だけが受け付ける)
+//           | "def" ident "(" [ params ] ")" "=" expr
+//           | expr
+//   expr    = lambda | binary
+//   lambda  = ( ident | "(" [ params ] ")" ) "->" expr   (無名関数。本体は右端まで続く)
+//   binary  = unary { binop unary }   (binop の優先順位と結合性は operatorTable に従う)
+//   unary   = prefixop unary | postfix   (前置の単項演算子。既定では + と -)
+//   postfix = primary { "(" [ expr { "," expr } ] ")" }   (関数呼び出し。mult(2)(5) も可)
+//   primary = number | ident | "(" expr ")"
+//   params  = ident { "," ident }
+//   - 「(2 + 3) * -4 / 2」は ((2 + 3) * (-4)) / 2 と解釈される
+//   - 同じ優先順位の二項演算子は結合性に従う (左結合なら「8 - 3 - 2」は (8 - 3) - 2)
+//   - 「def f(b) = 式」は「let f = (b) -> 式」と同じ (関数に名前が付く点だけが違う)
+
+// 構文木のノード
+//   - 各ノードは元になったトークンの位置 pos を持ち，評価時のエラー位置に使う
+//   - 数値はテキストのまま持ち，評価モード (int / big) ごとに変換する
+type node interface {
+	position() int
+}
+
+type numberNode struct {
+	pos  int
+	text string
+}
+
+type unaryNode struct {
+	pos     int
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	pos         int // 演算子の位置
+	op          string
+	left, right node
+}
+
+type letNode struct {
+	pos   int // 変数名の位置
+	name  string
+	value node
+}
+
+type identNode struct {
+	pos  int
+	name string
+}
+
+type callNode struct {
+	pos  int // 呼び出す関数 (式) の位置
+	fn   node
+	args []node
+}
+
+type lambdaNode struct {
+	pos    int
+	name   string // def で定義した関数の名前 (無名関数なら "")
+	params []string
+	body   node
+}
+
+func (n numberNode) position() int { return n.pos }
+func (n unaryNode) position() int  { return n.pos }
+func (n binaryNode) position() int { return n.pos }
+func (n letNode) position() int    { return n.pos }
+func (n identNode) position() int  { return n.pos }
+func (n callNode) position() int   { return n.pos }
+func (n lambdaNode) position() int { return n.pos }
+
+type parser struct {
+	ops    *operatorTable
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+// n 個先のトークン (入力の終わりを越えたら EOF)
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// 予期しないトークンに対するエラー
+func unexpected(t token) error {
+	if t.kind == tokenEOF {
+		return errorAt(t.pos, catalog.ErrorOf(errSyntax, msgSyntaxEOF))
+	}
+	return errorAt(t.pos, catalog.ErrorOf(errSyntax, msgSyntaxUnexpected, t.text))
+}
+
+func parse(input string, ops *operatorTable) (node, error) {
+	n, err := parseTokens(input, ops, false)
+	if err != nil {
+		return nil, withInput(err, input)
+	}
+	return n, nil
+}
+
+// 式または let 文・def 文を解析する
+func parseStatement(input string, ops *operatorTable) (node, error) {
+	n, err := parseTokens(input, ops, true)
+	if err != nil {
+		return nil, withInput(err, input)
+	}
+	return n, nil
+}
+
+func parseTokens(input string, ops *operatorTable, allowStatement bool) (node, error) {
+	tokens, err := tokenize(input, ops)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{ops: ops, tokens: tokens}
+	var n node
+	switch t := p.peek(); {
+	case allowStatement && t.kind == tokenIdent && t.text == "let":
+		n, err = p.parseLet()
+	case allowStatement && t.kind == tokenIdent && t.text == "def":
+		n, err = p.parseDef()
+	default:
+		n, err = p.parseExpr()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF { // 余分なトークンが残っている
+		return nil, unexpected(t)
+	}
+	return n, nil
+}
+
+// 代入先や引数の名前を読む
+//   - 「_」と「$1」などは REPL が結果の参照に使うので代入できない
+func (p *parser) parseName() (token, error) {
+	name := p.next()
+	if name.kind != tokenIdent || name.text == "_" || strings.HasPrefix(name.text, "$") {
+		return name, errorAt(name.pos, catalog.ErrorOf(errSyntax, msgSyntaxBadName))
+	}
+	return name, nil
+}
+
+func (p *parser) expectEquals() error {
+	if eq := p.next(); eq.kind != tokenOp || eq.text != "=" {
+		return errorAt(eq.pos, catalog.ErrorOf(errSyntax, msgSyntaxMissing, "="))
+	}
+	return nil
+}
+
+// let 名前 = 式
+func (p *parser) parseLet() (node, error) {
+	p.next() // let
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectEquals(); err != nil {
+		return nil, err
+	}
+	value, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return letNode{name.pos, name.text, value}, nil
+}
+
+// def 名前(引数, ...) = 式
+func (p *parser) parseDef() (node, error) {
+	def := p.next() // def
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokenLParen {
+		return nil, errorAt(t.pos, catalog.ErrorOf(errSyntax, msgSyntaxMissing, "("))
+	}
+	params, err := p.parseParams()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectEquals(); err != nil {
+		return nil, err
+	}
+	body, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return letNode{name.pos, name.text, lambdaNode{def.pos, name.text, params, body}}, nil
+}
+
+// 引数名のリスト (「(」の直後から「)」まで) を読む
+func (p *parser) parseParams() ([]string, error) {
+	var params []string
+	if p.peek().kind == tokenRParen {
+		p.next()
+		return params, nil
+	}
+	for {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, name.text)
+		switch t := p.next(); t.kind {
+		case tokenComma:
+			continue
+		case tokenRParen:
+			return params, nil
+		default:
+			return nil, errorAt(t.pos, catalog.ErrorOf(errSyntax, msgSyntaxMissing, ")"))
+		}
+	}
+}
+
+// この位置から無名関数が始まるか (「x ->」か「(a, b) ->」の形か) を先読みで調べる
+func (p *parser) lambdaAhead() bool {
+	if p.peek().kind == tokenIdent {
+		return p.peekAt(1).kind == tokenArrow
+	}
+	if p.peek().kind != tokenLParen {
+		return false
+	}
+	if p.peekAt(1).kind == tokenRParen {
+		return p.peekAt(2).kind == tokenArrow
+	}
+	for i := 1; ; i += 2 {
+		if p.peekAt(i).kind != tokenIdent {
+			return false
+		}
+		switch p.peekAt(i + 1).kind {
+		case tokenComma:
+			continue
+		case tokenRParen:
+			return p.peekAt(i+2).kind == tokenArrow
+		default:
+			return false
+		}
+	}
+}
+
+func (p *parser) parseExpr() (node, error) {
+	if !p.lambdaAhead() {
+		return p.parseBinary(1)
+	}
+	start := p.peek()
+	var params []string
+	if start.kind == tokenIdent {
+		params = []string{p.next().text}
+	} else {
+		p.next() // (
+		var err error
+		if params, err = p.parseParams(); err != nil {
+			return nil, err
+		}
+	}
+	p.next() // ->
+	body, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return lambdaNode{start.pos, "", params, body}, nil
+}
+
+func (p *parser) parseBinary(minPrec int) (node, error) {
+	lhs, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenOp {
+			return lhs, nil
+		}
+		syntax, ok := p.ops.binary[t.text]
+		if !ok {
+			return nil, errorAt(t.pos, catalog.ErrorOf(errUnknownOp, msgUnknownOpSymbol, t.text))
+		}
+		if syntax.prec < minPrec {
+			return lhs, nil
+		}
+		p.next()
+		// 左結合なら右側を1つ強い優先順位で読み，同じ優先順位の演算子を右側に取り込まない
+		nextPrec := syntax.prec + 1
+		if syntax.assoc == rightAssoc {
+			nextPrec = syntax.prec
+		}
+		rhs, err := p.parseBinary(nextPrec)
+		if err != nil {
+			return nil, err
+		}
+		lhs = binaryNode{t.pos, t.text, lhs, rhs}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if t := p.peek(); t.kind == tokenOp && p.ops.prefix[t.text] {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{t.pos, t.text, operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenLParen {
+		p.next()
+		args, err := p.parseArgs()
+		if err != nil {
+			return nil, err
+		}
+		n = callNode{n.position(), n, args}
+	}
+	return n, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		return numberNode{t.pos, t.text}, nil
+	case tokenIdent:
+		return identNode{t.pos, t.text}, nil
+	case tokenLParen:
+		n, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, errorAt(closing.pos, catalog.ErrorOf(errSyntax, msgSyntaxMissing, ")"))
+		}
+		return n, nil
+	case tokenOp:
+		if _, ok := p.ops.binary[t.text]; !ok {
+			return nil, errorAt(t.pos, catalog.ErrorOf(errUnknownOp, msgUnknownOpSymbol, t.text))
+		}
+	}
+	return nil, unexpected(t)
+}
+
+// 関数呼び出しの引数リスト (「(」の直後から「)」まで) を読む
+func (p *parser) parseArgs() ([]node, error) {
+	var args []node
+	if p.peek().kind == tokenRParen {
+		p.next()
+		return args, nil
+	}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		switch t := p.next(); t.kind {
+		case tokenComma:
+			continue
+		case tokenRParen:
+			return args, nil
+		default:
+			return nil, errorAt(t.pos, catalog.ErrorOf(errSyntax, msgSyntaxMissing, ")"))
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"learning-go/internal/catalog"
+)
+
+// 電卓の REPL (calc コマンド)
+//   go run . calc [-mode number|int|strict|big] [-division truncated|floored|euclidean] [-history ファイル]
+//   - 標準入力から1行ずつ読んで評価し，結果を $1, $2, ... という名前で表示する
+//     - 「_」で直前の結果，「$1」で1番目の結果を参照できる
+//   - let x = 2 * 3 で変数を，def mult(b) = x -> b * x で関数を定義できる
+//   - メタコマンド: :vars (変数の一覧), :ops (演算子と関数の一覧), :simplify 式, :help, :quit
+//     - :hex, :bin, :oct, :dec で整数の結果を表示する基数を切り替える
+//       (「:bin 4」のように数を付けると，その桁数ごとに _ で区切る)
+//   - 入力した行は履歴ファイル (既定は ~/.calc_history) に追記する
+
+const replHelp = `式を入力すると評価します (例: (2 + 3) * -4 / 2)
+  let x = 式   変数 x を定義する
+  def f(a, b) = 式
+               関数 f を定義する
+  x -> 式      無名関数 (例: let double = x -> x * 2)
+  _            直前の結果
+  $1, $2, ...  1番目，2番目，... の結果
+  :vars        変数の一覧
+  :ops         演算子と関数の一覧
+  :simplify 式 式を簡約して整形する (定数の計算，x * 1 → x など)
+  :hex [n]     整数の結果を16進数で表示する (n 桁ごとに _ で区切る)
+  :bin [n]     2進数で表示する
+  :oct [n]     8進数で表示する
+  :dec [n]     10進数で表示する (既定)
+  :help        このヘルプ
+  :quit        終了する`
+
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".calc_history")
+}
+
+func calcCommand(args []string) int {
+	fs := flag.NewFlagSet("calc", flag.ContinueOnError)
+	mode := fs.String("mode", "number", "評価モード (number, int, strict, big)")
+	divisionFlag := fs.String("division", "truncated", "div, mod, // の定義 (truncated, floored, euclidean)")
+	historyFile := fs.String("history", defaultHistoryFile(), "履歴ファイル (空なら保存しない)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	division, err := parseDivisionMode(*divisionFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var history io.Writer = io.Discard
+	if *historyFile != "" {
+		f, err := os.OpenFile(*historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		history = f
+	}
+
+	switch *mode {
+	case "number":
+		err = repl(&evaluator[number]{arith: numberArithWithDivision(division)}, os.Stdin, os.Stdout, history)
+	case "int":
+		err = repl(&evaluator[int]{arith: intArithWithDivision(intArith, division)}, os.Stdin, os.Stdout, history)
+	case "strict":
+		err = repl(&evaluator[int]{arith: intArithWithDivision(strictArith, division)}, os.Stdin, os.Stdout, history)
+	case "big":
+		err = repl(&evaluator[*big.Rat]{arith: bigArithWithDivision(division)}, os.Stdin, os.Stdout, history)
+	default:
+		fmt.Fprintln(os.Stderr, catalog.Message(msgUnknownMode, *mode))
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func repl[T any](e *evaluator[T], in io.Reader, out io.Writer, history io.Writer) error {
+	globals := e.global()
+	scanner := bufio.NewScanner(in)
+	results := 0
+	output := outputFormat{base: 10}
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		line := scanner.Text()
+		command := strings.TrimSpace(line)
+		if command == "" {
+			continue
+		}
+		fmt.Fprintln(history, line)
+
+		if strings.HasPrefix(command, ":") {
+			if command == ":quit" || command == ":q" {
+				return nil
+			}
+			replMeta(e, command, out, &output)
+			continue
+		}
+
+		n, v, err := e.run(line)
+		if err != nil {
+			printError(out, err)
+			continue
+		}
+		globals.vars["_"] = v
+		if let, ok := n.(letNode); ok {
+			fmt.Fprintln(out, let.name, "=", e.formatIn(v, output))
+			continue
+		}
+		results++
+		name := "$" + strconv.Itoa(results)
+		globals.vars[name] = v
+		fmt.Fprintln(out, name, "=", e.formatIn(v, output))
+	}
+}
+
+// エラーを入力とキャレット付きで表示する
+//   - 深すぎる再帰のエラーは関数を定義した行を指すことがあるので，表示する入力はエラーから取り出す
+func printError(out io.Writer, err error) {
+	fmt.Fprintln(out, catalog.Message(msgErrorPrefix, err))
+	var exprErr *exprError
+	if errors.As(err, &exprErr) {
+		fmt.Fprintln(out, "  "+exprErr.input)
+		fmt.Fprintln(out, "  "+exprErr.caret())
+	}
+}
+
+var outputBases = map[string]int{":hex": 16, ":bin": 2, ":oct": 8, ":dec": 10}
+
+func replMeta[T any](e *evaluator[T], command string, out io.Writer, output *outputFormat) {
+	name, arg, _ := strings.Cut(command, " ")
+	switch name {
+	case ":vars":
+		var names []string
+		for name := range e.global().vars {
+			if name != "_" && !strings.HasPrefix(name, "$") {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(out, "(変数はありません)")
+			return
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			fmt.Fprintln(out, name, "=", e.formatIn(e.global().vars[name], *output))
+		}
+	case ":ops":
+		ops := e.arith.syntax
+		var binary []string
+		for op := range ops.binary {
+			binary = append(binary, op)
+		}
+		// 優先順位の高い順 (同じなら記号順) に並べる
+		slices.SortFunc(binary, func(a, b string) int {
+			if ops.binary[a].prec != ops.binary[b].prec {
+				return ops.binary[b].prec - ops.binary[a].prec
+			}
+			return strings.Compare(a, b)
+		})
+		fmt.Fprintln(out, "二項演算子:")
+		for _, op := range binary {
+			fmt.Fprintf(out, "  %-3s 優先順位 %d %v\n", op, ops.binary[op].prec, ops.binary[op].assoc)
+		}
+		prefix := slices.Sorted(maps.Keys(ops.prefix))
+		fmt.Fprintln(out, "前置演算子:")
+		fmt.Fprintln(out, "  "+strings.Join(prefix, " "))
+		if len(e.arith.funcs) == 0 {
+			return
+		}
+		funcs := slices.Sorted(maps.Keys(e.arith.funcs))
+		fmt.Fprintln(out, "関数:")
+		for _, name := range funcs {
+			fmt.Fprintf(out, "  %s (引数 %d 個)\n", name, e.arith.funcs[name].arity)
+		}
+	case ":simplify":
+		simplified, problems, err := normalize(e.arith, strings.TrimSpace(arg))
+		if err != nil {
+			printError(out, err)
+			return
+		}
+		fmt.Fprintln(out, simplified)
+		for _, p := range problems {
+			printError(out, p)
+		}
+	case ":hex", ":bin", ":oct", ":dec":
+		group := 0
+		if arg = strings.TrimSpace(arg); arg != "" {
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 {
+				fmt.Fprintln(out, catalog.Message(msgBadGroup, arg))
+				return
+			}
+			group = n
+		}
+		*output = outputFormat{base: outputBases[name], group: group}
+	case ":help":
+		fmt.Fprintln(out, replHelp)
+	default:
+		fmt.Fprintln(out, catalog.Message(msgUnknownCommand, command))
+	}
+}
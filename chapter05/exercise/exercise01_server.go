@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"learning-go/internal/catalog"
+)
+
+// 電卓の HTTP JSON API (serve コマンド)
+//   go run . serve [-addr 127.0.0.1:8080] [-mode number|int|strict|big] [-division truncated|floored|euclidean] [-timeout 1s] [-max-len 1024]
+//   - POST /eval        {"expr": "2 + 3"}            → {"expr": "2 + 3", "result": "5"}
+//   - POST /eval/batch  {"exprs": ["let x = 2", "x * 3"]} → {"results": [...], "failed": 0}
+//     - batch の式は順に同じ環境で評価するので，前の式の let / def を後の式から使える
+//   - 評価に失敗したら error に種類 (errorKind の名前)・メッセージ・何文字目かを入れる
+//     - 式の値が関数 (「x -> x」など) なら数値として返せないので type の失敗にする (def や let は成功)
+//     {"expr": "1 / 0", "error": {"kind": "div_by_zero", "message": "3文字目: ...", "column": 3}}
+//   - 状態コード
+//     - /eval: 成功 200，評価の失敗 422，式が長すぎる 413，時間切れ 504，リクエストの形が不正 400
+//     - /eval/batch: リクエストが正しければ 200 で，式ごとの成否は results に入れる
+//     - どちらも本文が大きすぎれば 413 (too_long) にする
+//   - div, mod と演算子 // は calc や batch と同じく -division の定義に従う (division.go)
+//   - 1リクエストの評価は -timeout で打ち切る (関数呼び出しのたびに打ち切られたかを調べる)
+//   - 同じマシンの他のサービスから使う想定なので，既定では 127.0.0.1 でだけ待ち受ける
+
+type serverConfig struct {
+	timeout    time.Duration // 1リクエストの評価にかけてよい時間
+	maxExprLen int           // 式の最大のバイト数
+	maxBatch   int           // batch で一度に送れる式の数
+}
+
+var defaultServerConfig = serverConfig{
+	timeout:    time.Second,
+	maxExprLen: 1024,
+	maxBatch:   100,
+}
+
+type evalRequest struct {
+	Expr string `json:"expr"`
+}
+
+type batchRequest struct {
+	Exprs []string `json:"exprs"`
+}
+
+type apiError struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Column  int    `json:"column,omitempty"` // エラーのあった位置 (何文字目か。位置がなければ省く)
+}
+
+type evalResponse struct {
+	Expr   string    `json:"expr"`
+	Result string    `json:"result,omitempty"`
+	Error  *apiError `json:"error,omitempty"`
+}
+
+type batchResponse struct {
+	Results []evalResponse `json:"results"`
+	Failed  int            `json:"failed"`
+}
+
+// 式ではなくリクエスト自体の誤り (errorKind にない種類)
+const (
+	kindBadRequest = "bad_request"
+	kindTooLong    = "too_long"
+)
+
+type calcServer[T any] struct {
+	arith  arith[T]
+	config serverConfig
+}
+
+func newCalcServer[T any](a arith[T], config serverConfig) http.Handler {
+	s := &calcServer[T]{arith: a, config: config}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /eval", s.handleEval)
+	mux.HandleFunc("POST /eval/batch", s.handleBatch)
+	return mux
+}
+
+// リクエストの本文の上限 (式の長さの上限とは別に，巨大な本文を読み込まないようにする)
+func (s *calcServer[T]) maxBody() int64 {
+	return int64(s.config.maxExprLen*s.config.maxBatch) + 4096
+}
+
+func (s *calcServer[T]) handleEval(w http.ResponseWriter, r *http.Request) {
+	var req evalRequest
+	if err := decodeRequest(w, r, s.maxBody(), &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.timeout)
+	defer cancel()
+	e := &evaluator[T]{arith: s.arith, ctx: ctx}
+	resp := s.eval(e, req.Expr)
+	status := http.StatusOK
+	if resp.Error != nil {
+		switch resp.Error.Kind {
+		case kindTooLong:
+			status = http.StatusRequestEntityTooLarge
+		case "timeout":
+			status = http.StatusGatewayTimeout
+		default:
+			status = http.StatusUnprocessableEntity
+		}
+	}
+	writeJSON(w, status, resp)
+}
+
+func (s *calcServer[T]) handleBatch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	err := decodeRequest(w, r, s.maxBody(), &req)
+	if err == nil && len(req.Exprs) > s.config.maxBatch {
+		err = catalog.NewError(msgTooManyExprs, s.config.maxBatch)
+	}
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.timeout)
+	defer cancel()
+	e := &evaluator[T]{arith: s.arith, ctx: ctx}
+	resp := batchResponse{Results: make([]evalResponse, 0, len(req.Exprs))}
+	for _, expr := range req.Exprs {
+		result := s.eval(e, expr)
+		if result.Error != nil {
+			resp.Failed++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// 1つの式を評価する (let 文・def 文も使える)
+func (s *calcServer[T]) eval(e *evaluator[T], expr string) evalResponse {
+	resp := evalResponse{Expr: expr}
+	if len(expr) > s.config.maxExprLen {
+		resp.Error = &apiError{Kind: kindTooLong, Message: catalog.Message(msgTooLong, s.config.maxExprLen)}
+		return resp
+	}
+	if e.ctx.Err() != nil { // batch で前の式までに時間を使い切った
+		resp.Error = toAPIError(errTimeout)
+		return resp
+	}
+	n, v, err := e.run(expr)
+	if _, isLet := n.(letNode); err == nil && v.fn != nil && !isLet { // def や let で関数に名前を付けるのはよい
+		err = withInput(errorAt(n.position(), catalog.ErrorOf(errType, msgTypeFuncResult)), expr)
+	}
+	if err != nil {
+		resp.Error = toAPIError(err)
+		return resp
+	}
+	resp.Result = e.format(v)
+	return resp
+}
+
+func toAPIError(err error) *apiError {
+	apiErr := &apiError{Kind: errorKind(err), Message: err.Error()}
+	var exprErr *exprError
+	if errors.As(err, &exprErr) && exprErr.input != "" {
+		apiErr.Column = exprErr.runeOffset() + 1
+	}
+	return apiErr
+}
+
+// JSON の本文を1つだけ読む (知らないフィールドや後ろに続くゴミは誤りにする)
+func decodeRequest(w http.ResponseWriter, r *http.Request, limit int64, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return catalog.NewError(msgTrailingData)
+	}
+	return nil
+}
+
+// リクエスト自体の誤りを返す (本文が上限を超えたら 413，それ以外は 400)
+func writeRequestError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		apiErr := &apiError{Kind: kindTooLong, Message: catalog.Message(msgBodyTooLarge, tooLarge.Limit)}
+		writeJSON(w, http.StatusRequestEntityTooLarge, evalResponse{Error: apiErr})
+		return
+	}
+	writeJSON(w, http.StatusBadRequest, evalResponse{Error: &apiError{Kind: kindBadRequest, Message: err.Error()}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil { // 状態コードは送った後なので，接続が切れたなどはログに残すだけにする
+		log.Print(catalog.Message(msgWriteResponse, err))
+	}
+}
+
+func serveCommand(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "127.0.0.1:8080", "待ち受けるアドレス")
+	mode := fs.String("mode", "number", "評価モード (number, int, strict, big)")
+	divisionFlag := fs.String("division", "truncated", "div, mod, // の定義 (truncated, floored, euclidean)")
+	config := defaultServerConfig
+	fs.DurationVar(&config.timeout, "timeout", config.timeout, "1リクエストの評価の制限時間")
+	fs.IntVar(&config.maxExprLen, "max-len", config.maxExprLen, "式の最大のバイト数")
+	fs.IntVar(&config.maxBatch, "max-batch", config.maxBatch, "batch で一度に送れる式の数")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	division, err := parseDivisionMode(*divisionFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var handler http.Handler
+	switch *mode {
+	case "number":
+		handler = newCalcServer(numberArithWithDivision(division), config)
+	case "int":
+		handler = newCalcServer(intArithWithDivision(intArith, division), config)
+	case "strict":
+		handler = newCalcServer(intArithWithDivision(strictArith, division), config)
+	case "big":
+		handler = newCalcServer(bigArithWithDivision(division), config)
+	default:
+		fmt.Fprintln(os.Stderr, catalog.Message(msgUnknownMode, *mode))
+		return 2
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      config.timeout + 10*time.Second,
+	}
+	fmt.Fprintln(os.Stderr, "待ち受けています:", *addr)
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
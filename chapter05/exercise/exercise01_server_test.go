@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"learning-go/internal/division"
+)
+
+// 時間切れを確かめるため，制限時間を短くした number モードのサーバを立てる (除算の定義は floored)
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(newCalcServer(numberArithWithDivision(division.Floored), serverConfig{
+		timeout:    200 * time.Millisecond,
+		maxExprLen: 1024,
+		maxBatch:   10,
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func post(t *testing.T, url, body string) (int, string) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(b)
+}
+
+func TestServerEval(t *testing.T) {
+	server := newTestServer(t)
+	cases := []struct {
+		name   string
+		body   string
+		status int
+		want   string // 応答に含まれるべき文字列
+	}{
+		{"int", `{"expr": "(2 + 3) * -4 / 2"}`, http.StatusOK, `"result":"-10"`},
+		{"builtin", `{"expr": "sqrt(2)"}`, http.StatusOK, `"result":"1.4142135623730951"`},
+		{"div by zero", `{"expr": "1 / 0"}`, http.StatusUnprocessableEntity, `"kind":"div_by_zero"`},
+		{"syntax", `{"expr": "2 +"}`, http.StatusUnprocessableEntity, `"column":4`},
+		{"undefined", `{"expr": "foo(1)"}`, http.StatusUnprocessableEntity, `"kind":"undefined"`},
+		{"function result", `{"expr": "x -> x"}`, http.StatusUnprocessableEntity, `"kind":"type"`},
+		{"def", `{"expr": "def f(x) = x * 2"}`, http.StatusOK, `"result":"`},
+		{"floored div", `{"expr": "-7 // 2 + mod(-7, 2) * 10"}`, http.StatusOK, `"result":"6"`},
+		{"div of floats", `{"expr": "div(7.5, 2)"}`, http.StatusUnprocessableEntity, `"kind":"type"`},
+		{"too long", `{"expr": "` + strings.Repeat("1+", 600) + `1"}`, http.StatusRequestEntityTooLarge, `"kind":"too_long"`},
+		// 本文の上限 (1024 * 10 + 4096 バイト) を超えると，式の長さを調べる前に読むのをやめる
+		{"body too large", `{"expr": "` + strings.Repeat("1+", 10000) + `1"}`, http.StatusRequestEntityTooLarge, `"kind":"too_long"`},
+		// 3 回適用する関数を重ねて 3 の 27 乗回の呼び出しになる (呼び出しの深さは浅いまま)
+		{"timeout", `{"expr": "(t -> t(t)(t)(x -> x + 1)(0))(f -> x -> f(f(f(x))))"}`, http.StatusGatewayTimeout, `"kind":"timeout"`},
+		{"unknown field", `{"exp": "1"}`, http.StatusBadRequest, `"kind":"bad_request"`},
+		{"not json", `not json`, http.StatusBadRequest, `"kind":"bad_request"`},
+		{"trailing data", `{"expr": "1"} {}`, http.StatusBadRequest, `"kind":"bad_request"`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, body := post(t, server.URL+"/eval", c.body)
+			if status != c.status || !strings.Contains(body, c.want) {
+				t.Errorf("POST /eval %s: %d %s, want %d with %s", c.body, status, body, c.status, c.want)
+			}
+		})
+	}
+}
+
+func TestServerBatch(t *testing.T) {
+	server := newTestServer(t)
+	cases := []struct {
+		name   string
+		exprs  []string
+		failed int
+	}{
+		{"let and def", []string{"let x = 2", "def f(y) = x * y", "f(21)"}, 0},
+		{"def a function", []string{"def f(x) = x * 2", "f(3)"}, 0},
+		{"let a lambda", []string{"let g = x -> x + 1", "g(1)"}, 0},
+		{"errors", []string{"1", "2 *", "1 / 0", "x -> x"}, 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			body, _ := json.Marshal(batchRequest{Exprs: c.exprs})
+			status, respBody := post(t, server.URL+"/eval/batch", string(body))
+			if status != http.StatusOK {
+				t.Fatalf("POST /eval/batch: %d %s", status, respBody)
+			}
+			var resp batchResponse
+			if err := json.Unmarshal([]byte(respBody), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Failed != c.failed || len(resp.Results) != len(c.exprs) {
+				t.Errorf("POST /eval/batch %q: %s, want failed %d", c.exprs, respBody, c.failed)
+			}
+		})
+	}
+
+	body, _ := json.Marshal(batchRequest{Exprs: []string{"let x = 2", "def f(y) = x * y", "f(21)"}})
+	_, respBody := post(t, server.URL+"/eval/batch", string(body))
+	if !strings.Contains(respBody, `"result":"42"`) {
+		t.Errorf("batch result: %s", respBody)
+	}
+
+	status, respBody := post(t, server.URL+"/eval/batch", `{"exprs": ["1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"]}`)
+	if status != http.StatusBadRequest || !strings.Contains(respBody, `"kind":"bad_request"`) {
+		t.Errorf("too many exprs: %d %s", status, respBody)
+	}
+
+	status, respBody = post(t, server.URL+"/eval/batch", `{"exprs": ["`+strings.Repeat("1+", 10000)+`1"]}`)
+	if status != http.StatusRequestEntityTooLarge || !strings.Contains(respBody, `"kind":"too_long"`) {
+		t.Errorf("body too large: %d %s", status, respBody)
+	}
+}
+
+// POST 以外は受け付けない
+func TestServerMethod(t *testing.T) {
+	server := newTestServer(t)
+	resp, err := http.Get(server.URL + "/eval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET /eval: %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+// 書き込めない応答 (クライアントが接続を切ったときなど)
+type brokenResponseWriter struct {
+	httptest.ResponseRecorder
+}
+
+func (w *brokenResponseWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+// 応答を書き込めなかったことをログに残す
+func TestWriteJSONLogsError(t *testing.T) {
+	var logs strings.Builder
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	writeJSON(&brokenResponseWriter{}, http.StatusOK, evalResponse{Expr: "1", Result: "1"})
+	if !strings.Contains(logs.String(), "connection reset") {
+		t.Errorf("log = %q, want the write error", logs.String())
+	}
+}
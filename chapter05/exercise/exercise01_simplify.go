@@ -0,0 +1,198 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// 構文木の簡約
+//   - 葉の方から順に次の書き換えをする
+//     - 定数畳み込み: 数値だけの部分式を計算し，結果の数値に置き換える (「2 * 3 + x」→「6 + x」)
+//     - 恒等式: x * 1, 1 * x, x / 1, x + 0, 0 + x, x - 0 → x，x * 0, 0 * x → 0
+//       (0 * x は x が未定義の名前や NaN でも 0 にする。代数的な規則として扱う)
+//       - ただし値に種類のあるモード (number) では 0 * x を書き換えない
+//         (x が 2.5 なら 0.0，1+1i なら (0+0i) になるので，int の 0 と書くと後の計算の結果が変わる)
+//   - 定数の 0 で割る式 (「x / 0」「x / (2 - 2)」) は実行すれば必ず失敗するので，位置付きのエラーとして報告する
+//     - 報告しても簡約は続け，その部分式は書き換えずに残す
+//   - 計算の結果が数値リテラルとして書けない値 (複素数や分数など) なら畳み込まない
+//     - リテラルは arith の text で書き (number の float64 は 3.0)，読み直して同じ値にならなければ畳み込まない
+//       (表示の「3」を書くと int の 3 になり，(1.5 + 1.5) / 2 が 1 になってしまう)
+//   - 関数呼び出しは畳み込まない (組み込み関数の名前が変数で隠されているかもしれない)
+
+type simplifier[T any] struct {
+	arith    arith[T]
+	eval     *evaluator[T]
+	problems []error
+}
+
+// n を簡約した構文木と，実行すれば必ず失敗する箇所の一覧を返す
+func simplify[T any](a arith[T], n node) (node, []error) {
+	s := &simplifier[T]{arith: a, eval: &evaluator[T]{arith: a}}
+	return s.node(n), s.problems
+}
+
+// 入力を解析・簡約・整形する (保存する前の式の正規化に使う)
+//   - 報告された箇所のエラーには入力が結びついている
+func normalize[T any](a arith[T], input string) (string, []error, error) {
+	n, err := parseStatement(input, a.syntax)
+	if err != nil {
+		return "", nil, err
+	}
+	n, problems := simplify(a, n)
+	for i, p := range problems {
+		problems[i] = withInput(p, input)
+	}
+	return formatNode(n, a.syntax), problems, nil
+}
+
+func (s *simplifier[T]) node(n node) node {
+	switch n := n.(type) {
+	case unaryNode:
+		n.operand = s.node(n.operand)
+		if folded, ok := s.fold(n); ok {
+			return folded
+		}
+		return n
+	case binaryNode:
+		n.left = s.node(n.left)
+		n.right = s.node(n.right)
+		if r, ok := s.constant(n.right); ok && s.alwaysFails(n.op, r) {
+			s.problems = append(s.problems, errorAt(n.pos, errDivByZero))
+			return n
+		}
+		if folded, ok := s.fold(n); ok {
+			return folded
+		}
+		return s.identity(n)
+	case callNode:
+		n.fn = s.node(n.fn)
+		args := make([]node, len(n.args))
+		for i, arg := range n.args {
+			args[i] = s.node(arg)
+		}
+		n.args = args
+		return n
+	case lambdaNode:
+		n.body = s.node(n.body)
+		return n
+	case letNode:
+		n.value = s.node(n.value)
+		return n
+	}
+	return n
+}
+
+// n が定数 (数値か，符号の付いた数値) ならその値を返す
+func (s *simplifier[T]) constant(n node) (T, bool) {
+	var zero T
+	switch n := n.(type) {
+	case numberNode:
+	case unaryNode:
+		if _, ok := n.operand.(numberNode); !ok {
+			return zero, false
+		}
+	default:
+		return zero, false
+	}
+	v, err := s.eval.numIn(n, s.eval.global())
+	return v, err == nil
+}
+
+// 定数 r で割ると，割られる数によらず必ず 0 除算になるか
+func (s *simplifier[T]) alwaysFails(op string, r T) bool {
+	f, ok := s.arith.ops[op]
+	if !ok {
+		return false
+	}
+	_, err := f(s.arith.zero, r)
+	return errors.Is(err, errDivByZero)
+}
+
+// 子がすべて定数なら計算した結果の数値に置き換える (置き換えたら true)
+func (s *simplifier[T]) fold(n node) (node, bool) {
+	switch n := n.(type) {
+	case unaryNode:
+		if _, ok := s.constant(n.operand); !ok {
+			return nil, false
+		}
+	case binaryNode:
+		_, l := s.constant(n.left)
+		_, r := s.constant(n.right)
+		if !l || !r {
+			return nil, false
+		}
+	}
+	v, err := s.eval.numIn(n, s.eval.global())
+	if err != nil { // 桁あふれなどは実行時に任せる
+		return nil, false
+	}
+	return s.literal(n.position(), v)
+}
+
+// 値 v を表す数値リテラル (負なら前置の - を付ける) を作る
+func (s *simplifier[T]) literal(pos int, v T) (node, bool) {
+	text := s.arith.literalText(v)
+	abs, negative := strings.CutPrefix(text, "-")
+	// +Inf や 2/3 のように，1つの数値として読めない表示なら書き換えない
+	tokens, err := tokenize(abs, s.arith.syntax)
+	if err != nil || len(tokens) != 2 || tokens[0].kind != tokenNumber {
+		return nil, false
+	}
+	lit := node(numberNode{pos, abs})
+	if negative {
+		if !s.arith.syntax.prefix["-"] {
+			return nil, false
+		}
+		lit = unaryNode{pos, "-", lit}
+	}
+	back, err := s.eval.numIn(lit, s.eval.global())
+	if err != nil || s.arith.literalText(back) != text {
+		return nil, false
+	}
+	return lit, true
+}
+
+// 値を数値リテラルとして書く
+func (a arith[T]) literalText(v T) string {
+	if a.text != nil {
+		return a.text(v)
+	}
+	return a.format(v)
+}
+
+// 恒等式による書き換え
+func (s *simplifier[T]) identity(n binaryNode) node {
+	is := func(side node, text string) bool {
+		num, ok := side.(numberNode)
+		return ok && num.text == text
+	}
+	switch n.op {
+	case "*":
+		switch {
+		case is(n.left, "0") && !s.arith.kinds:
+			return n.left
+		case is(n.right, "0") && !s.arith.kinds:
+			return n.right
+		case is(n.right, "1"):
+			return n.left
+		case is(n.left, "1"):
+			return n.right
+		}
+	case "/":
+		if is(n.right, "1") {
+			return n.left
+		}
+	case "+":
+		switch {
+		case is(n.right, "0"):
+			return n.left
+		case is(n.left, "0"):
+			return n.right
+		}
+	case "-":
+		if is(n.right, "0") {
+			return n.left
+		}
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math/big"
+	"testing"
+)
+
+// 簡約して整形した式を読み直して評価すると，元の式と同じ値 (同じ種類) になるか
+//   - 保存するのは整形した文字列なので，構文木ではなく文字列から評価し直す
+func checkSimplified[T any](t *testing.T, a arith[T], vars map[string]T, expressions []string) {
+	t.Helper()
+	evalIn := func(input string) (string, error) {
+		e := &evaluator[T]{arith: a}
+		for name, v := range vars {
+			e.global().vars[name] = value[T]{num: v}
+		}
+		_, v, err := e.run(input)
+		return a.literalText(v.num), err
+	}
+	for _, expr := range expressions {
+		normalized, problems, err := normalize(a, expr)
+		if err != nil || len(problems) > 0 {
+			t.Errorf("normalize(%q): %v %v", expr, err, problems)
+			continue
+		}
+		want, wantErr := evalIn(expr)
+		got, err := evalIn(normalized)
+		if (err == nil) != (wantErr == nil) || got != want {
+			t.Errorf("%q → %q: %s (%v), want %s (%v)", expr, normalized, got, err, want, wantErr)
+		}
+	}
+}
+
+func TestSimplifyKeepsValue(t *testing.T) {
+	checkSimplified(t, numberArith, map[string]number{"x": intValue(7), "y": floatValue(2.5), "z": complexValue(1 + 1i)}, []string{
+		"(1.5 + 1.5) / 2",
+		"0.5 * 2 / 4",
+		"2.0 * 3 + x / 2",
+		"-(4.5 - 0.5) + x",
+		"1e300 * 1e10",
+		"(2 + 3) * 4 - x * 1 + 0",
+		"(x + 0) / (y * 1)",
+		"7 / 2 * y",
+		"def f(a) = a * (1.0 + 1) / 4",
+		// 0 * x の種類は x で決まる (float64 の 0.0 や complex128 の (0+0i) を int の 0 にしない)
+		"(y * 0 + 1) / 2",
+		"(0 * y + 3) / 2",
+		"(z * 0 + 3) / 2",
+		"(z - 1i) * 1 + 0",
+		"y * 1 / 1 - 0 + z * 0",
+		"x * 0 + y / 2",
+	})
+	checkSimplified(t, intArith, map[string]int{"x": 7}, []string{
+		"(1 + 2) * x - 10 / 4",
+		"x * 0 + 1",
+		"-(2 - 5) * x",
+		"x / (3 - 1)",
+	})
+	checkSimplified(t, bigArith, map[string]*big.Rat{"x": big.NewRat(7, 1)}, []string{
+		"(1 + 2) * x - 10 / 4",
+		"2 / 3 + 1 / 3 + x",
+	})
+}
+
+// 正規化した文字列が want になるか
+func checkNormalized[T any](t *testing.T, a arith[T], cases map[string]string) {
+	t.Helper()
+	for expr, want := range cases {
+		got, _, err := normalize(a, expr)
+		if err != nil || got != want {
+			t.Errorf("normalize(%q) = %q (%v), want %q", expr, got, err, want)
+		}
+	}
+}
+
+// 値の種類が変わる畳み込みをしない (float64 の 3 を int の 3 と書かない)
+func TestSimplifyFloatLiteral(t *testing.T) {
+	checkNormalized(t, numberArith, map[string]string{
+		"(1.5 + 1.5) / 2": "1.5",
+		"0.5 * 2 / 4":     "0.25",
+		"3.0":             "3.0",
+		"6.0 / 2 + x":     "3.0 + x",
+		"-(2.0 * 2)":      "-4.0",
+	})
+}
+
+// 0 * x → 0 は値に種類のないモードでだけ書き換える
+func TestSimplifyZeroProduct(t *testing.T) {
+	checkNormalized(t, intArith, map[string]string{
+		"(x * 0 + 1) / 2": "0",
+		"0 * x + y":       "y",
+	})
+	checkNormalized(t, bigArith, map[string]string{
+		"x * 0 + 1": "1",
+	})
+	checkNormalized(t, numberArith, map[string]string{
+		"(y * 0 + 1) / 2": "(y * 0 + 1) / 2",
+		"0 * z + 3":       "0 * z + 3",
+		"x * 1 + 0":       "x",
+	})
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"learning-go/internal/catalog"
+)
+
+// バイトコードへのコンパイルとスタックマシン
+//   - 同じ式をデータの行ごとに何度も評価する場合，毎回の字句解析・構文解析・strconv.Atoi が無駄になる
+//   - compile で式を一度だけ命令列 (program) にし，machine で変数の値を変えながら何度も実行する
+//     - 数値リテラルはコンパイル時に値に変換して定数表に入れる
+//     - 演算子・組み込み関数はコンパイル時に表を引いておき，実行時は添字で呼び出す
+//     - 変数は名前ではなく番号 (スロット) で参照する
+//   - 使えるのは数値・変数・演算子・組み込み関数の式だけ (let, def, 無名関数は使えない)
+//   - 呼び出しは評価器と同じく変数を先に探すので，変数と同じ名前の関数は呼び出さずにコンパイルできないことにする
+//     - 式の中で変数としても使う名前 (実行時に値を束縛するので，評価器では数値を呼び出すことになる)
+//     - evaluator.compile では，let / def で定義済みの名前 (評価器ではその値や関数を呼び出す)
+
+type opcode byte
+
+const (
+	opConst  opcode = iota // 定数表の arg 番目を積む
+	opLoad                 // 変数の arg 番目を積む
+	opBinary               // 2つ降ろして二項演算子の arg 番目を適用し，結果を積む
+	opPrefix               // 1つ降ろして前置演算子の arg 番目を適用し，結果を積む
+	opCall                 // 組み込み関数の arg 番目の引数の数だけ降ろして呼び出し，結果を積む
+)
+
+var opcodeNames = [...]string{"CONST", "LOAD", "BINARY", "PREFIX", "CALL"}
+
+type instr struct {
+	op  opcode
+	arg int
+	pos int // 元の式での位置 (エラー表示用)
+}
+
+type program[T any] struct {
+	code     []instr
+	consts   []T
+	ops      []opFunc[T]
+	opNames  []string
+	prefix   []func(T) (T, error)
+	funcs    []builtin[T]
+	vars     []string // スロット番号 → 変数名
+	maxStack int
+	source   string
+	format   func(T) string
+}
+
+var errNotCompilable = catalog.NewError(msgNotCompilable)
+
+type compiler[T any] struct {
+	arith arith[T]
+	env   *scope[T] // 定義済みの変数 (nil ならない)
+	prog  *program[T]
+	index map[string]int // 演算子・関数・変数の名前 → 各表での番号 (種類ごとに接頭辞を付けて区別する)
+	calls []identNode    // 呼び出した組み込み関数の名前
+	depth int
+}
+
+// 式をバイトコードにコンパイルする
+func compile[T any](a arith[T], input string) (*program[T], error) {
+	return compileIn(a, nil, input)
+}
+
+// 評価器で let / def した名前を踏まえて式をバイトコードにコンパイルする
+func (e *evaluator[T]) compile(input string) (*program[T], error) {
+	return compileIn(e.arith, e.global(), input)
+}
+
+func compileIn[T any](a arith[T], env *scope[T], input string) (*program[T], error) {
+	n, err := parse(input, a.syntax)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiler[T]{
+		arith: a,
+		env:   env,
+		prog:  &program[T]{source: input, format: a.format},
+		index: map[string]int{},
+	}
+	if err := c.compile(n); err != nil {
+		return nil, withInput(err, input)
+	}
+	for _, ident := range c.calls { // 変数は呼び出しより後に現れることもあるので，最後に調べる
+		if c.prog.slot(ident.name) >= 0 {
+			return nil, withInput(errorAt(ident.pos, catalog.ErrorOf(errNotCompilable, msgNotCompilableVar, ident.name)), input)
+		}
+	}
+	return c.prog, nil
+}
+
+func (c *compiler[T]) emit(op opcode, arg, pos, stackDelta int) {
+	c.prog.code = append(c.prog.code, instr{op, arg, pos})
+	c.depth += stackDelta
+	c.prog.maxStack = max(c.prog.maxStack, c.depth)
+}
+
+// 表の中での番号を返す (初めての名前なら add で追加する)
+func (c *compiler[T]) indexOf(key string, add func() int) int {
+	if i, ok := c.index[key]; ok {
+		return i
+	}
+	i := add()
+	c.index[key] = i
+	return i
+}
+
+func (c *compiler[T]) binary(pos int, op string) error {
+	f, ok := c.arith.ops[op]
+	if !ok {
+		return errorAt(pos, catalog.ErrorOf(errUnknownOp, msgUnknownOpSymbol, op))
+	}
+	i := c.indexOf("op:"+op, func() int {
+		c.prog.ops = append(c.prog.ops, f)
+		c.prog.opNames = append(c.prog.opNames, op)
+		return len(c.prog.ops) - 1
+	})
+	c.emit(opBinary, i, pos, -1)
+	return nil
+}
+
+func (c *compiler[T]) constant(pos int, v T) {
+	c.prog.consts = append(c.prog.consts, v)
+	c.emit(opConst, len(c.prog.consts)-1, pos, 1)
+}
+
+func (c *compiler[T]) compile(n node) error {
+	switch n := n.(type) {
+	case numberNode:
+		v, err := c.arith.literal(n.text)
+		if err != nil {
+			return errorAt(n.pos, catalog.ErrorOf(errSyntax, msgSyntaxCause, err))
+		}
+		c.constant(n.pos, v)
+		return nil
+	case identNode:
+		i := c.indexOf("var:"+n.name, func() int {
+			c.prog.vars = append(c.prog.vars, n.name)
+			return len(c.prog.vars) - 1
+		})
+		c.emit(opLoad, i, n.pos, 1)
+		return nil
+	case unaryNode:
+		f, ok := c.arith.prefix[n.op]
+		if !ok { // 0 - x として計算する
+			c.constant(n.pos, c.arith.zero)
+			if err := c.compile(n.operand); err != nil {
+				return err
+			}
+			return c.binary(n.pos, n.op)
+		}
+		if err := c.compile(n.operand); err != nil {
+			return err
+		}
+		i := c.indexOf("prefix:"+n.op, func() int {
+			c.prog.prefix = append(c.prog.prefix, f)
+			return len(c.prog.prefix) - 1
+		})
+		c.emit(opPrefix, i, n.pos, 0)
+		return nil
+	case binaryNode:
+		if err := c.compile(n.left); err != nil {
+			return err
+		}
+		if err := c.compile(n.right); err != nil {
+			return err
+		}
+		return c.binary(n.pos, n.op)
+	case callNode:
+		ident, ok := n.fn.(identNode)
+		if !ok {
+			return errorAt(n.pos, catalog.ErrorOf(errNotCompilable, msgNotCompilableCall))
+		}
+		if _, ok := c.env.lookup(ident.name); ok {
+			return errorAt(n.pos, catalog.ErrorOf(errNotCompilable, msgNotCompilableVar, ident.name))
+		}
+		f, ok := c.arith.funcs[ident.name]
+		if !ok {
+			return errorAt(n.pos, catalog.ErrorOf(errUndefined, msgUndefinedName, ident.name))
+		}
+		c.calls = append(c.calls, ident)
+		if len(n.args) != f.arity {
+			return errorAt(n.pos, catalog.ErrorOf(errArgs, msgArgsCount, ident.name, f.arity))
+		}
+		for _, arg := range n.args {
+			if err := c.compile(arg); err != nil {
+				return err
+			}
+		}
+		i := c.indexOf("func:"+ident.name, func() int {
+			c.prog.funcs = append(c.prog.funcs, f)
+			return len(c.prog.funcs) - 1
+		})
+		c.emit(opCall, i, n.pos, 1-f.arity)
+		return nil
+	case lambdaNode:
+		return errorAt(n.pos, catalog.ErrorOf(errNotCompilable, msgNotCompilableFunc))
+	}
+	return errorAt(n.position(), errNotCompilable)
+}
+
+// 命令列を人が読める形にする
+func (p *program[T]) String() string {
+	var b strings.Builder
+	for i, in := range p.code {
+		fmt.Fprintf(&b, "%3d  %-6s %d", i, opcodeNames[in.op], in.arg)
+		switch in.op {
+		case opConst:
+			fmt.Fprintf(&b, "  (%s)", p.format(p.consts[in.arg]))
+		case opLoad:
+			fmt.Fprintf(&b, "  (%s)", p.vars[in.arg])
+		case opBinary:
+			fmt.Fprintf(&b, "  (%s)", p.opNames[in.arg])
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// 変数名と値の組からスロット順の値の並びを作る
+func (p *program[T]) bind(vars map[string]T) ([]T, error) {
+	values := make([]T, len(p.vars))
+	for i, name := range p.vars {
+		v, ok := vars[name]
+		if !ok {
+			return nil, catalog.ErrorOf(errUndefined, msgUndefinedName, name)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// 変数のスロット番号 (式に現れなければ -1)
+func (p *program[T]) slot(name string) int {
+	return slices.Index(p.vars, name)
+}
+
+// スタックマシン
+//   - スタックを使い回すので，1つの machine を複数のゴルーチンで同時に使ってはいけない
+type machine[T any] struct {
+	prog  *program[T]
+	stack []T
+}
+
+func (p *program[T]) machine() *machine[T] {
+	return &machine[T]{prog: p, stack: make([]T, p.maxStack)}
+}
+
+// vars はスロット順の変数の値 (program.vars と同じ並び)
+func (m *machine[T]) run(vars []T) (T, error) {
+	var zero T
+	p := m.prog
+	if len(vars) < len(p.vars) {
+		return zero, catalog.ErrorOf(errArgs, msgArgsVars, len(p.vars))
+	}
+	stack := m.stack
+	sp := 0
+	for _, in := range p.code {
+		switch in.op {
+		case opConst:
+			stack[sp] = p.consts[in.arg]
+			sp++
+		case opLoad:
+			stack[sp] = vars[in.arg]
+			sp++
+		case opBinary:
+			sp--
+			r, err := p.ops[in.arg](stack[sp-1], stack[sp])
+			if err != nil {
+				return zero, withInput(errorAt(in.pos, err), p.source)
+			}
+			stack[sp-1] = r
+		case opPrefix:
+			r, err := p.prefix[in.arg](stack[sp-1])
+			if err != nil {
+				return zero, withInput(errorAt(in.pos, err), p.source)
+			}
+			stack[sp-1] = r
+		case opCall:
+			f := p.funcs[in.arg]
+			sp -= f.arity
+			r, err := f.fn(stack[sp : sp+f.arity])
+			if err != nil {
+				return zero, withInput(errorAt(in.pos, err), p.source)
+			}
+			stack[sp] = r
+			sp++
+		}
+	}
+	return stack[0], nil
+}
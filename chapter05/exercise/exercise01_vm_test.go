@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// バイトコードと評価器が同じ式・同じ変数の値で同じ結果 (エラーかどうかも含めて) になるか
+func TestVMMatchesEvaluator(t *testing.T) {
+	expressions := []string{
+		"(x + 3) * (y - 2) / 4 + x * y - 10 * (x - y)",
+		"x / y",
+		"-x + +y",
+		"abs(x - y) * 2.5",
+		"pow(x, 2) - sqrt(4) + real(complex(x, y))",
+		"(x + 1i) * y",
+	}
+	rows := []map[string]number{
+		{"x": intValue(7), "y": intValue(2)},
+		{"x": intValue(-7), "y": intValue(0)},
+		{"x": floatValue(1.5), "y": intValue(3)},
+		{"x": complexValue(2 + 1i), "y": floatValue(0.5)},
+	}
+	for _, expr := range expressions {
+		prog, err := compile(numberArith, expr)
+		if err != nil {
+			t.Fatalf("compile(%q): %v", expr, err)
+		}
+		m := prog.machine()
+		for _, row := range rows {
+			e := &evaluator[number]{arith: numberArith}
+			for name, v := range row {
+				e.global().vars[name] = value[number]{num: v}
+			}
+			_, want, wantErr := e.run(expr)
+			vars, err := prog.bind(row)
+			if err != nil {
+				t.Fatalf("bind(%q, %v): %v", expr, row, err)
+			}
+			got, gotErr := m.run(vars)
+			if (wantErr == nil) != (gotErr == nil) || want.num != got {
+				t.Errorf("%q %v: vm = %v (%v), evaluator = %v (%v)", expr, row, got, gotErr, want.num, wantErr)
+			}
+		}
+	}
+}
+
+// 評価器で組み込み関数ではなく変数が呼ばれる名前は，バイトコードにしない
+func TestVMRefusesShadowedCalls(t *testing.T) {
+	for _, expr := range []string{"abs(abs)", "sqrt(2) + sqrt", "pow(x, 2) * pow"} {
+		if _, err := compile(numberArith, expr); !errors.Is(err, errNotCompilable) {
+			t.Errorf("compile(%q) error = %v, want errNotCompilable", expr, err)
+		}
+	}
+
+	e := &evaluator[number]{arith: numberArith}
+	if _, err := e.compile("abs(-3)"); err != nil { // まだ定義していなければ組み込み関数を呼ぶ
+		t.Fatalf("compile before def: %v", err)
+	}
+	for _, stmt := range []string{"def abs(x) = x * 2", "let sqrt = 4"} {
+		if _, _, err := e.run(stmt); err != nil {
+			t.Fatalf("%q: %v", stmt, err)
+		}
+	}
+	for _, expr := range []string{"abs(-3)", "sqrt(16)"} {
+		if _, err := e.compile(expr); !errors.Is(err, errNotCompilable) {
+			t.Errorf("compile(%q) after def error = %v, want errNotCompilable", expr, err)
+		}
+	}
+	if _, err := e.compile("pow(2, 3)"); err != nil { // 定義していない名前はそのまま呼べる
+		t.Errorf("compile(pow) after def: %v", err)
+	}
+}
@@ -1,31 +1,19 @@
 package main
 
 import (
+	"compress/gzip"
 	"fmt"
-	"io"
 	"os"
+	"strings"
 )
 
+// ファイルのバイト数 (統計は exercise02_stats.go で 1 回の読み込みでまとめて数える)
 func fileLen(fileName string) (int, error) {
-	f, err := os.Open(fileName)
+	stats, err := fileStatsOf(fileName)
 	if err != nil {
 		return 0, err
 	}
-	defer f.Close()
-
-	totalLen := 0
-	data := make([]byte, 2048)
-	for {
-		count, err := f.Read(data)
-		if err != nil {
-			if err != io.EOF {
-				return 0, err
-			}
-			break
-		}
-		totalLen += count
-	}
-	return totalLen, nil
+	return stats.bytes, nil
 }
 
 func exercise02() {
@@ -35,4 +23,20 @@ func exercise02() {
 		return
 	}
 	fmt.Println("size:", size)
+
+	// gzip で圧縮したファイルも展開した中身の大きさを返す
+	if gz, err := os.CreateTemp("", "exercise02-*.go.gz"); err == nil {
+		zw := gzip.NewWriter(gz)
+		src, _ := os.ReadFile("exercise02.go")
+		zw.Write(src)
+		zw.Close()
+		gz.Close()
+		gzSize, err := fileLen(gz.Name())
+		fmt.Println("gzip:", gzSize, err, gzSize == size) // gzip: (size と同じ値) <nil> true
+		os.Remove(gz.Name())
+	}
+
+	// 日本語はバイト数と文字数が違う。\xff は不正な UTF-8
+	stats, _ := readStats(strings.NewReader("こんにちは　世界\nHello, World!\n\xff\n"))
+	fmt.Printf("%+v\n", stats) // {bytes:41 runes:25 lines:3 words:5 invalid:1}
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"learning-go/internal/fileio"
+)
+
+// ファイルの読み込みの速さとバッファの割り当ての比較
+//   go test -run '^$' -bench Read -benchmem .
+//   - 1回の操作は「ファイルを開いて最後まで読んで閉じる」(小さなファイルをたくさん読む場面を想定)
+//     - 読んだデータはすべて一度ずつ見る (改行を数える)
+//     - make: ファイルごとに make でバッファを作る (もとの fileLen や processFile と同じ)
+//     - pool: fileio.ReadChunks でプールのバッファを使い回す
+//   - バッファの大きさ (100, 2048, 32768 バイト) ごとに，1ファイルあたりの時間・割り当て・スループットを表示する
+//     - 割り当てには os.Open の分も含まれるので，make と pool の差がバッファの分になる
+//   - BenchmarkReadMmap は mmap (fileio.ReadFile) と 2048 バイトずつの読み込み (pool) を比べる
+//     - 読んだデータをすべて見るのは，mmap では触ったページだけが読まれるため
+//     - mmap はファイルが大きいほど有利 (1回ごとに対応付けと解除をするので，小さなファイルでは遅くなる)
+
+// 大きさ size の試験用のファイルを作る
+func writeBenchFile(b *testing.B, size int) string {
+	name := filepath.Join(b.TempDir(), "data")
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = "abcdefghijklmnopqrstuvwxyz\n"[i%27]
+	}
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	return name
+}
+
+func countLines(lines *int) func(chunk []byte) error {
+	return func(chunk []byte) error {
+		*lines += bytes.Count(chunk, []byte{'\n'})
+		return nil
+	}
+}
+
+// ファイルごとに make でバッファを作って読む
+func readWithMake(name string, size int, fn func(chunk []byte) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	buf := make([]byte, size)
+	for {
+		count, err := f.Read(buf)
+		fn(buf[:count])
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func readWithPool(name string, size int, fn func(chunk []byte) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return fileio.ReadChunks(f, size, fn)
+}
+
+// ファイルを1つ読む操作を計測する (スループットは fileSize バイトから求める)
+func benchmarkRead(b *testing.B, fileSize int, read func(lines *int) error) {
+	b.ReportAllocs()
+	b.SetBytes(int64(fileSize))
+	lines := 0
+	for b.Loop() {
+		if err := read(&lines); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReadChunks(b *testing.B) {
+	const fileSize = 4096
+	name := writeBenchFile(b, fileSize)
+	for _, size := range []int{100, 2048, 32768} {
+		b.Run("make/"+strconv.Itoa(size), func(b *testing.B) {
+			benchmarkRead(b, fileSize, func(lines *int) error {
+				return readWithMake(name, size, countLines(lines))
+			})
+		})
+		b.Run("pool/"+strconv.Itoa(size), func(b *testing.B) {
+			benchmarkRead(b, fileSize, func(lines *int) error {
+				return readWithPool(name, size, countLines(lines))
+			})
+		})
+	}
+}
+
+func BenchmarkReadMmap(b *testing.B) {
+	for _, fileSize := range []int{4096, 64 << 20} {
+		name := writeBenchFile(b, fileSize)
+		b.Run("pool/"+strconv.Itoa(fileSize), func(b *testing.B) {
+			benchmarkRead(b, fileSize, func(lines *int) error {
+				return readWithPool(name, 2048, countLines(lines))
+			})
+		})
+		b.Run("mmap/"+strconv.Itoa(fileSize), func(b *testing.B) {
+			fileio.MmapThreshold = 1 // 大きさによらず mmap で読む
+			defer func() { fileio.MmapThreshold = 0 }()
+			benchmarkRead(b, fileSize, func(lines *int) error {
+				return fileio.ReadFile(name, 2048, countLines(lines))
+			})
+		})
+	}
+}
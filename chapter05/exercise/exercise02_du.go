@@ -0,0 +1,294 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"learning-go/internal/fileio"
+)
+
+// ディレクトリごとの合計サイズ (du コマンド)
+//   go run . du [-j 8] [-L] [-read] [-mmap 0] [-s] [-timeout 0] [ディレクトリ...]
+//   - ディレクトリの木をたどり，ディレクトリごとにその下 (サブディレクトリも含む) のファイルの合計バイト数を表示する
+//   - 決まった数 (-j) のゴルーチンがディレクトリの待ち行列から1つずつ取り出して読む
+//     - ディレクトリの数だけゴルーチンを作らないので，同時に開くディレクトリの数も -j 以下になる
+//   - シンボリックリンクは既定ではたどらず，リンク自体の大きさを数える
+//     - -L でリンク先をたどる。同じディレクトリを2回数えないように，実際のパスで訪問済みかを調べる
+//   - サイズは既定では Stat の大きさ。-read なら fileLen で実際に読んだバイト数を数える
+//     (圧縮されたファイルは展開した大きさになる。-mmap でそのバイト数以上のファイルは mmap で読む)
+//   - 読めないファイルやディレクトリがあっても止めずに続け，最後にすべてのエラーをまとめて返す (errors.Join)
+//   - ctx が取り消されたら (Ctrl-C や -timeout) 新しいディレクトリを読むのをやめる
+
+type duOptions struct {
+	workers int  // 同時に読むディレクトリの数
+	follow  bool // シンボリックリンクをたどる
+	read    bool // ファイルを読んで大きさを数える
+}
+
+// 読むべきディレクトリの待ち行列
+//   - pending は積んだがまだ読み終えていないディレクトリの数。0 になればすべて終わり
+type dirQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	dirs    []string
+	pending int
+}
+
+func newDirQueue() *dirQueue {
+	q := &dirQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *dirQueue) push(dir string) {
+	q.mu.Lock()
+	q.dirs = append(q.dirs, dir)
+	q.pending++
+	q.mu.Unlock()
+	q.cond.Signal()
+}
+
+// 次のディレクトリを取り出す (すべて終わったか取り消されたら false)
+func (q *dirQueue) pop(ctx context.Context) (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.dirs) == 0 && q.pending > 0 && ctx.Err() == nil {
+		q.cond.Wait()
+	}
+	if len(q.dirs) == 0 || ctx.Err() != nil {
+		return "", false
+	}
+	dir := q.dirs[len(q.dirs)-1]
+	q.dirs = q.dirs[:len(q.dirs)-1]
+	return dir, true
+}
+
+func (q *dirQueue) done() {
+	q.mu.Lock()
+	q.pending--
+	finished := q.pending == 0
+	q.mu.Unlock()
+	if finished {
+		q.cond.Broadcast()
+	}
+}
+
+type sizeWalker struct {
+	ctx     context.Context
+	opts    duOptions
+	queue   *dirQueue
+	mu      sync.Mutex
+	own     map[string]int64 // ディレクトリの直下にあるファイルの合計
+	depth   map[string]int   // root からの深さ (root は 0)
+	visited map[string]bool  // -L のとき，訪問したディレクトリの実際のパス
+	errs    []error
+}
+
+// root の下のディレクトリごとの合計 (root がファイルならそのファイルだけ)
+//   - エラーがあっても読めた分の合計は返す
+func dirSizes(ctx context.Context, root string, opts duOptions) (map[string]int64, error) {
+	info, err := os.Stat(root) // 引数に指定したパスはリンクでもたどる
+	if err != nil {
+		return nil, err
+	}
+	w := &sizeWalker{
+		ctx:     ctx,
+		opts:    opts,
+		queue:   newDirQueue(),
+		own:     map[string]int64{},
+		depth:   map[string]int{},
+		visited: map[string]bool{},
+	}
+	if !info.IsDir() {
+		size, err := w.fileSize(root, info)
+		return map[string]int64{root: size}, err
+	}
+
+	w.firstVisit(root)
+	w.enqueue(root, 0)
+	stop := context.AfterFunc(ctx, func() { // 取り消されたら待っているゴルーチンを起こす
+		w.queue.mu.Lock()
+		w.queue.mu.Unlock()
+		w.queue.cond.Broadcast()
+	})
+	defer stop()
+	var wg sync.WaitGroup
+	for range max(w.opts.workers, 1) {
+		wg.Go(func() {
+			for {
+				dir, ok := w.queue.pop(ctx)
+				if !ok {
+					return
+				}
+				w.readDir(dir)
+				w.queue.done()
+			}
+		})
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		w.errs = append(w.errs, err)
+	}
+	return w.total(root), errors.Join(w.errs...)
+}
+
+func (w *sizeWalker) readDir(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		w.fail(err) // 読めたところまでの entries は数える
+	}
+	var sum int64
+	for _, entry := range entries {
+		if w.ctx.Err() != nil {
+			break
+		}
+		path := filepath.Join(dir, entry.Name())
+		var info fs.FileInfo
+		var err error
+		if w.opts.follow && entry.Type()&fs.ModeSymlink != 0 {
+			info, err = os.Stat(path)
+		} else {
+			info, err = entry.Info()
+		}
+		if err != nil {
+			w.fail(err)
+			continue
+		}
+		if info.IsDir() {
+			if w.firstVisit(path) {
+				w.enqueue(path, w.depthOf(dir)+1)
+			}
+			continue
+		}
+		size, err := w.fileSize(path, info)
+		if err != nil {
+			w.fail(err)
+			continue
+		}
+		sum += size
+	}
+	w.mu.Lock()
+	w.own[dir] += sum
+	w.mu.Unlock()
+}
+
+func (w *sizeWalker) enqueue(dir string, depth int) {
+	w.mu.Lock()
+	w.depth[dir] = depth
+	w.mu.Unlock()
+	w.queue.push(dir)
+}
+
+func (w *sizeWalker) depthOf(dir string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.depth[dir]
+}
+
+func (w *sizeWalker) fileSize(path string, info fs.FileInfo) (int64, error) {
+	if !w.opts.read || !info.Mode().IsRegular() {
+		return info.Size(), nil
+	}
+	size, err := fileLen(path)
+	return int64(size), err
+}
+
+// ディレクトリを初めて訪れたなら true (-L でなければリンクの先に入らないので，いつも true)
+func (w *sizeWalker) firstVisit(dir string) bool {
+	if !w.opts.follow {
+		return true
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		w.fail(err)
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.visited[resolved] {
+		return false
+	}
+	w.visited[resolved] = true
+	return true
+}
+
+func (w *sizeWalker) fail(err error) {
+	w.mu.Lock()
+	w.errs = append(w.errs, err)
+	w.mu.Unlock()
+}
+
+// 深いディレクトリから順に親へ足して，サブディレクトリを含む合計にする
+func (w *sizeWalker) total(root string) map[string]int64 {
+	dirs := make([]string, 0, len(w.own))
+	for dir := range w.own {
+		dirs = append(dirs, dir)
+	}
+	slices.SortFunc(dirs, func(a, b string) int { return cmp.Compare(w.depth[b], w.depth[a]) })
+	totals := make(map[string]int64, len(w.own))
+	for _, dir := range dirs {
+		totals[dir] += w.own[dir]
+		if dir != root {
+			totals[filepath.Dir(dir)] += totals[dir]
+		}
+	}
+	return totals
+}
+
+func duCommand(args []string) int {
+	fs := flag.NewFlagSet("du", flag.ContinueOnError)
+	var opts duOptions
+	fs.IntVar(&opts.workers, "j", 8, "同時に読むディレクトリの数")
+	fs.BoolVar(&opts.follow, "L", false, "シンボリックリンクをたどる")
+	fs.BoolVar(&opts.read, "read", false, "ファイルを読んで大きさを数える (Stat の大きさを使わない)")
+	fs.Int64Var(&fileio.MmapThreshold, "mmap", 0, "-read のとき，このバイト数以上のファイルは mmap で読む (0 なら使わない)")
+	summary := fs.Bool("s", false, "指定したディレクトリの合計だけを表示する")
+	timeout := fs.Duration("timeout", 0, "この時間が過ぎたら打ち切る (0 なら打ち切らない)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	roots := fs.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	status := 0
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		sizes, err := dirSizes(ctx, root, opts)
+		if err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") { // errors.Join のエラーは1行に1つ
+				fmt.Fprintln(os.Stderr, "du:", line)
+			}
+			status = 1
+		}
+		if *summary {
+			if size, ok := sizes[root]; ok {
+				fmt.Printf("%d\t%s\n", size, root)
+			}
+			continue
+		}
+		for _, dir := range slices.Sorted(maps.Keys(sizes)) {
+			fmt.Printf("%d\t%s\n", sizes[dir], dir)
+		}
+	}
+	return status
+}
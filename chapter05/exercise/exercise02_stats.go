@@ -0,0 +1,129 @@
+package main
+
+import (
+	"io"
+	"unicode"
+	"unicode/utf8"
+
+	"learning-go/internal/fileio"
+)
+
+// ファイルの統計 (wc と同じ項目)
+//   - 1回読むだけで，バイト数・文字数 (ルーン数)・行数・単語数・不正な UTF-8 の数を数える
+//     - 日本語の文字は UTF-8 で 3 バイトなので，バイト数と文字数は一致しない (3章)
+//   - 文字数は utf8.RuneCount と同じく，不正なバイトも 1 バイトを 1 文字と数える
+//     - 不正なバイトの数は invalid に別に数える (for range で utf8.RuneError になる数)
+//   - 行数は '\n' の数，単語は unicode.IsSpace で区切る (全角の空白 U+3000 も区切りになる)
+//   - 読み込みの区切りで文字が 2 つに分かれても，次の Write までバイトを持ち越して正しく数える
+
+type fileStats struct {
+	bytes   int
+	runes   int
+	lines   int
+	words   int
+	invalid int
+}
+
+func (s *fileStats) add(t fileStats) {
+	s.bytes += t.bytes
+	s.runes += t.runes
+	s.lines += t.lines
+	s.words += t.words
+	s.invalid += t.invalid
+}
+
+// io.Writer として書き込まれたバイトを数える (最後に finish を呼ぶ)
+type statsCounter struct {
+	stats  fileStats
+	inWord bool
+	carry  []byte // 前の Write の末尾にあった，途中で切れた文字のバイト
+}
+
+func (c *statsCounter) Write(p []byte) (int, error) {
+	c.stats.bytes += len(p)
+	i := 0
+	if len(c.carry) > 0 {
+		// 持ち越したバイトと p の先頭をつないで，持ち越した分を読み終えるまで数える
+		var buf [2 * utf8.UTFMax]byte
+		head := append(buf[:0], c.carry...)
+		head = append(head, p[:min(len(p), utf8.UTFMax)]...)
+		carried := len(c.carry)
+		c.carry = c.carry[:0]
+		j := 0
+		for j < carried {
+			if !utf8.FullRune(head[j:]) { // p が短くてまだ文字がそろわない
+				c.carry = append(c.carry, head[j:]...)
+				return len(p), nil
+			}
+			r, size := utf8.DecodeRune(head[j:])
+			c.count(r, size)
+			j += size
+		}
+		i = j - carried
+	}
+	for i < len(p) {
+		if p[i] < utf8.RuneSelf {
+			c.count(rune(p[i]), 1)
+			i++
+			continue
+		}
+		if !utf8.FullRune(p[i:]) {
+			c.carry = append(c.carry, p[i:]...)
+			break
+		}
+		r, size := utf8.DecodeRune(p[i:])
+		c.count(r, size)
+		i += size
+	}
+	return len(p), nil
+}
+
+func (c *statsCounter) count(r rune, size int) {
+	c.stats.runes++
+	if r == utf8.RuneError && size == 1 {
+		c.stats.invalid++
+	}
+	if r == '\n' {
+		c.stats.lines++
+	}
+	space := unicode.IsSpace(r) // 不正なバイトは単語の一部として扱う
+	if !space && !c.inWord {
+		c.stats.words++
+	}
+	c.inWord = !space
+}
+
+// 入力の終わり: 持ち越したまま終わったバイトは不正なバイトとして数える
+func (c *statsCounter) finish() fileStats {
+	for range c.carry {
+		c.count(utf8.RuneError, 1)
+	}
+	c.carry = c.carry[:0]
+	return c.stats
+}
+
+// r を最後まで読んで統計を返す (fileLen と同じ 2048 バイトずつの読み込み。fileio.ReadChunks)
+func readStats(r io.Reader) (fileStats, error) {
+	var c statsCounter
+	err := fileio.ReadChunks(r, 2048, func(chunk []byte) error {
+		c.Write(chunk)
+		return nil
+	})
+	if err != nil {
+		return fileStats{}, err
+	}
+	return c.finish(), nil
+}
+
+// ファイルの統計 (圧縮されたファイルは展開した中身を数える。大きなファイルは mmap でも読める。fileio.ReadFile)
+func fileStatsOf(fileName string) (fileStats, error) {
+	var c statsCounter
+	err := fileio.ReadFile(fileName, 2048, func(chunk []byte) error {
+		c.Write(chunk)
+		return nil
+	})
+	if err != nil {
+		return fileStats{}, err
+	}
+	return c.finish(), nil
+}
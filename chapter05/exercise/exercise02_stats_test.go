@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// 統計の入力 (複数バイトの文字・不正なバイト・途中で切れた文字・CRLF を含む)
+var statsInputs = []string{
+	"",
+	"hello, world\n",
+	"日本語のテキスト\n二行目\n",
+	"€𝄞 x\r\ny\r\n",
+	"全角　空白　区切り",
+	"a\xffb\xfe\n",               // UTF-8 に現れないバイト
+	"\xe3\x81\xe3\x81\x82",       // 途中で切れた文字の後に正しい文字
+	"末尾で切れる\xf0\x9f\x98",         // 最後の文字がそろわないまま終わる
+	"\xc0\xaf \xed\xa0\x80 \x80", // 冗長な符号化・サロゲート・続きのバイトだけ
+	"� は正しい文字",                   // U+FFFD そのものは不正なバイトではない
+	"\r\n\r\n\n",
+}
+
+// 標準ライブラリで数えた統計 (statsCounter と独立に求める)
+func wantStats(s string) fileStats {
+	stats := fileStats{
+		bytes: len(s),
+		runes: utf8.RuneCountInString(s),
+		lines: strings.Count(s, "\n"),
+		words: len(strings.Fields(s)),
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			stats.invalid++
+		}
+		i += size
+	}
+	return stats
+}
+
+func countStats(chunks ...string) fileStats {
+	var c statsCounter
+	for _, chunk := range chunks {
+		if n, err := c.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			panic("statsCounter.Write must consume every byte")
+		}
+	}
+	return c.finish()
+}
+
+// 1回の Write で数えた統計が標準ライブラリと同じになるか
+func TestStatsCounter(t *testing.T) {
+	for _, s := range statsInputs {
+		if got, want := countStats(s), wantStats(s); got != want {
+			t.Errorf("%q: %+v, want %+v", s, got, want)
+		}
+	}
+}
+
+// どこで2回・3回の Write に分けても，1回の Write と同じ統計になるか
+//   - 文字の途中で分けたときの持ち越し (1バイトずつ届く場合も含む) を確かめる
+func TestStatsCounterSplitWrites(t *testing.T) {
+	for _, s := range statsInputs {
+		want := countStats(s)
+		for i := 0; i <= len(s); i++ {
+			if got := countStats(s[:i], s[i:]); got != want {
+				t.Errorf("%q split at %d: %+v, want %+v", s, i, got, want)
+			}
+			for j := i; j <= len(s); j++ {
+				if got := countStats(s[:i], s[i:j], s[j:]); got != want {
+					t.Errorf("%q split at %d and %d: %+v, want %+v", s, i, j, got, want)
+				}
+			}
+		}
+		single := make([]string, len(s))
+		for i := 0; i < len(s); i++ {
+			single[i] = s[i : i+1]
+		}
+		if got := countStats(single...); got != want {
+			t.Errorf("%q byte by byte: %+v, want %+v", s, got, want)
+		}
+	}
+}
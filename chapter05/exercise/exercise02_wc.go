@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"learning-go/internal/fileio"
+)
+
+// ファイルの統計 (wc コマンド)
+//   go run . wc [-l] [-w] [-m] [-c] [-invalid] [-sum sha256,crc32] [-mmap バイト数] [ファイル...]
+//   - 行数・単語数・文字数・バイト数・不正な UTF-8 の数をこの順に表示し，最後にファイル名を付ける
+//     - 項目を指定しなければすべて表示する
+//   - ファイルが2つ以上なら最後に合計 (total) を表示する
+//   - ファイルを省略するか「-」なら標準入力を読む
+//   - 読めないファイルはエラーを表示して次に進み，終了ステータスを 1 にする
+//   - -sum を指定すると，同じ読み込みで求めたダイジェストをファイル名の前に表示する (checksum.go)
+//   - -mmap を指定すると，そのバイト数以上のファイルを mmap で読む (internal/fileio)
+
+func wcCommand(args []string) int {
+	fs := flag.NewFlagSet("wc", flag.ContinueOnError)
+	lines := fs.Bool("l", false, "行数を表示する")
+	words := fs.Bool("w", false, "単語数を表示する")
+	runes := fs.Bool("m", false, "文字数を表示する")
+	bytes := fs.Bool("c", false, "バイト数を表示する")
+	invalid := fs.Bool("invalid", false, "不正な UTF-8 のバイト数を表示する")
+	sumList := fs.String("sum", "", "同時に求めるダイジェストの種類 (crc32, md5, sha1, sha256 をカンマで区切って指定する)")
+	fs.Int64Var(&fileio.MmapThreshold, "mmap", 0, "このバイト数以上のファイルは mmap で読む (0 なら使わない)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	algorithms, err := parseAlgorithms(*sumList)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if !*lines && !*words && !*runes && !*bytes && !*invalid {
+		*lines, *words, *runes, *bytes, *invalid = true, true, true, true, true
+	}
+	columns := func(s fileStats) []int {
+		var values []int
+		for _, c := range []struct {
+			show  bool
+			value int
+		}{{*lines, s.lines}, {*words, s.words}, {*runes, s.runes}, {*bytes, s.bytes}, {*invalid, s.invalid}} {
+			if c.show {
+				values = append(values, c.value)
+			}
+		}
+		return values
+	}
+
+	names := fs.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	type result struct {
+		name    string
+		stats   fileStats
+		digests []digest
+	}
+	var results []result
+	var total fileStats
+	status := 0
+	for _, name := range names {
+		var stats fileStats
+		var digests []digest
+		var err error
+		switch {
+		case len(algorithms) > 0:
+			stats, digests, err = scanPath(name, algorithms)
+		case name == "-":
+			stats, err = readStats(os.Stdin)
+		default:
+			stats, err = fileStatsOf(name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "wc:", err)
+			status = 1
+			continue
+		}
+		results = append(results, result{name, stats, digests})
+		total.add(stats)
+	}
+	if len(names) > 1 {
+		results = append(results, result{"total", total, nil})
+	}
+
+	// 桁をそろえる幅は合計の一番大きい値に合わせる (合計はどの値よりも大きい)
+	width := len(strconv.Itoa(max(total.bytes, total.runes)))
+	for _, r := range results {
+		var b strings.Builder
+		for _, v := range columns(r.stats) {
+			fmt.Fprintf(&b, "%*d ", width, v)
+		}
+		for _, d := range r.digests {
+			fmt.Fprintf(&b, "%x ", d.sum)
+		}
+		if r.name != "-" {
+			b.WriteString(r.name)
+		}
+		fmt.Println(strings.TrimRight(b.String(), " "))
+	}
+	return status
+}
@@ -1,6 +1,42 @@
 package main
 
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"learning-go/internal/catalog"
+)
+
+// サブコマンド (go run . calc のように指定する)
+//   - テストは go test .，ベンチマークは go test -run '^$' -bench . -benchmem .
+//   - 戻り値は終了ステータス
+//   - 指定がなければ練習問題を順に実行する
+//   - サブコマンドの前に -lang en のようにエラーメッセージの言語を指定できる (messages.go を参照)
+var commands = map[string]func(args []string) int{
+	"calc":  calcCommand,
+	"batch": batchCommand,
+	"serve": serveCommand,
+	"wc":    wcCommand,
+	"du":    duCommand,
+	"sum":   sumCommand,
+}
+
 func main() {
+	lang := flag.String("lang", "", "エラーメッセージの言語 (ja, en。空なら環境変数 LANG などから決める)")
+	flag.Parse()
+	if err := catalog.SetLanguage(*lang); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if flag.NArg() > 0 {
+		cmd, ok := commands[flag.Arg(0)]
+		if !ok {
+			fmt.Fprintln(os.Stderr, catalog.Message(msgUnknownCommand, flag.Arg(0)))
+			os.Exit(2)
+		}
+		os.Exit(cmd(flag.Args()[1:]))
+	}
 	exercise01()
 	exercise02()
 	exercise03()
@@ -0,0 +1,204 @@
+package main
+
+import "learning-go/internal/catalog"
+
+// メッセージカタログ (このディレクトリで使う文言。文言を引く仕組みは internal/catalog)
+//   - 利用者に見せるエラーの文言はメッセージ ID ごとに日本語 (ja) と英語 (en) を持つ
+//     - 文言は fmt の書式で，演算子の記号などの引数を埋め込む (語順が違えば %[2]s のように番号で指定する)
+
+const (
+	msgSyntax             catalog.ID = "syntax"
+	msgSyntaxCause        catalog.ID = "syntax.cause"
+	msgSyntaxEOF          catalog.ID = "syntax.eof"
+	msgSyntaxUnexpected   catalog.ID = "syntax.unexpected"
+	msgSyntaxBadChar      catalog.ID = "syntax.bad_char"
+	msgSyntaxBadName      catalog.ID = "syntax.bad_name"
+	msgSyntaxMissing      catalog.ID = "syntax.missing"
+	msgSyntaxRune         catalog.ID = "syntax.rune"
+	msgUnknownOp          catalog.ID = "unknown_op"
+	msgUnknownOpSymbol    catalog.ID = "unknown_op.symbol"
+	msgDivByZero          catalog.ID = "div_by_zero"
+	msgUndefined          catalog.ID = "undefined"
+	msgUndefinedName      catalog.ID = "undefined.name"
+	msgArgs               catalog.ID = "args"
+	msgArgsCount          catalog.ID = "args.count"
+	msgArgsComplex        catalog.ID = "args.complex"
+	msgArgsVars           catalog.ID = "args.vars"
+	msgType               catalog.ID = "type"
+	msgTypeFuncInArith    catalog.ID = "type.func_in_arith"
+	msgTypeNotCallable    catalog.ID = "type.not_callable"
+	msgTypeFuncResult     catalog.ID = "type.func_result"
+	msgTypeIntOnly        catalog.ID = "type.int_only"
+	msgDepth              catalog.ID = "depth"
+	msgTimeout            catalog.ID = "timeout"
+	msgOverflow           catalog.ID = "overflow"
+	msgOverflowBinary     catalog.ID = "overflow.binary"
+	msgOverflowNeg        catalog.ID = "overflow.neg"
+	msgNotCompilable      catalog.ID = "not_compilable"
+	msgNotCompilableCall  catalog.ID = "not_compilable.call"
+	msgNotCompilableFunc  catalog.ID = "not_compilable.lambda"
+	msgNotCompilableVar   catalog.ID = "not_compilable.var"
+	msgBadOperator        catalog.ID = "bad_operator"
+	msgBadOperatorEmpty   catalog.ID = "bad_operator.empty"
+	msgBadOperatorArrow   catalog.ID = "bad_operator.arrow"
+	msgBadOperatorEquals  catalog.ID = "bad_operator.equals"
+	msgBadOperatorRune    catalog.ID = "bad_operator.rune"
+	msgBadOperatorImpl    catalog.ID = "bad_operator.impl"
+	msgBadOperatorPrec    catalog.ID = "bad_operator.prec"
+	msgBadOperatorArity   catalog.ID = "bad_operator.arity"
+	msgNegativeExponent   catalog.ID = "negative_exponent"
+	msgNegativeShift      catalog.ID = "negative_shift"
+	msgBigLiteral         catalog.ID = "big_literal"
+	msgAt                 catalog.ID = "at"
+	msgInside             catalog.ID = "inside"
+	msgFunction           catalog.ID = "function"
+	msgErrorPrefix        catalog.ID = "error_prefix"
+	msgUnknownCommand     catalog.ID = "unknown_command"
+	msgUnknownMode        catalog.ID = "unknown_mode"
+	msgUnknownFormat      catalog.ID = "unknown_format"
+	msgUnknownDivision    catalog.ID = "unknown_division"
+	msgOneFile            catalog.ID = "one_file"
+	msgBadGroup           catalog.ID = "bad_group"
+	msgTooLong            catalog.ID = "too_long"
+	msgBodyTooLarge       catalog.ID = "too_long.body"
+	msgWriteResponse      catalog.ID = "write_response"
+	msgTooManyExprs       catalog.ID = "too_many_exprs"
+	msgTrailingData       catalog.ID = "trailing_data"
+	msgUnknownAlgorithm   catalog.ID = "unknown_algorithm"
+	msgChecksumMismatch   catalog.ID = "checksum_mismatch"
+	msgChecksumUnreadable catalog.ID = "checksum_unreadable"
+	msgChecksumBadLines   catalog.ID = "checksum_bad_lines"
+)
+
+func init() {
+	catalog.Register(messages)
+}
+
+var messages = catalog.Messages{
+	"ja": {
+		msgSyntax:             "不正な式です",
+		msgSyntaxCause:        "不正な式です: %v",
+		msgSyntaxEOF:          "不正な式です: 式が途中で終わっています",
+		msgSyntaxUnexpected:   "不正な式です: 予期しない %q があります",
+		msgSyntaxBadChar:      "不正な式です: 使えない文字 %q があります",
+		msgSyntaxBadName:      "不正な式です: 代入できない名前です",
+		msgSyntaxMissing:      "不正な式です: 「%s」がありません",
+		msgSyntaxRune:         "不正な式です: ルーンリテラルが閉じていません",
+		msgUnknownOp:          "定義されていない演算子です",
+		msgUnknownOpSymbol:    "定義されていない演算子です: %s",
+		msgDivByZero:          "0で割ることはできません",
+		msgUndefined:          "定義されていない名前です",
+		msgUndefinedName:      "定義されていない名前です: %s",
+		msgArgs:               "引数が正しくありません",
+		msgArgsCount:          "引数が正しくありません: %v は引数を %d 個とります",
+		msgArgsComplex:        "引数が正しくありません: complex の引数は実数です",
+		msgArgsVars:           "引数が正しくありません: 変数の値が %d 個必要です",
+		msgType:               "値の種類が正しくありません",
+		msgTypeFuncInArith:    "値の種類が正しくありません: 関数は計算に使えません",
+		msgTypeNotCallable:    "値の種類が正しくありません: 関数ではないものは呼び出せません",
+		msgTypeFuncResult:     "値の種類が正しくありません: 結果が関数です",
+		msgTypeIntOnly:        "値の種類が正しくありません: div, mod, // は整数にだけ使えます",
+		msgDepth:              "関数呼び出しが深すぎます",
+		msgTimeout:            "制限時間内に計算が終わりませんでした",
+		msgOverflow:           "桁あふれしました",
+		msgOverflowBinary:     "桁あふれしました: %v %s %v (%T)",
+		msgOverflowNeg:        "桁あふれしました: -(%v) (%T)",
+		msgNotCompilable:      "バイトコードにできません",
+		msgNotCompilableCall:  "バイトコードにできません: 組み込み関数以外は呼び出せません",
+		msgNotCompilableFunc:  "バイトコードにできません: 無名関数は使えません",
+		msgNotCompilableVar:   "バイトコードにできません: %s は変数として定義されているので組み込み関数として呼び出せません",
+		msgBadOperator:        "演算子を登録できません",
+		msgBadOperatorEmpty:   "演算子を登録できません: 記号が空です",
+		msgBadOperatorArrow:   "演算子を登録できません: -> は無名関数に使います",
+		msgBadOperatorEquals:  "演算子を登録できません: = で始まる記号は let と def の = と紛らわしいので使えません",
+		msgBadOperatorRune:    "演算子を登録できません: %q は記号に使えません",
+		msgBadOperatorImpl:    "演算子を登録できません: %s の実装 (%s) がありません",
+		msgBadOperatorPrec:    "演算子を登録できません: %s の優先順位は1以上にしてください",
+		msgBadOperatorArity:   "演算子を登録できません: %s の引数の数は1か2です",
+		msgNegativeExponent:   "負の指数は使えません",
+		msgNegativeShift:      "負のシフト数は使えません",
+		msgBigLiteral:         "整数として解釈できません: %s",
+		msgAt:                 "%d文字目: %v",
+		msgInside:             "%v の中で: %v",
+		msgFunction:           "<関数 %s(%s)>",
+		msgErrorPrefix:        "エラー: %v",
+		msgUnknownCommand:     "不明なコマンドです: %s",
+		msgUnknownMode:        "不明なモードです: %s",
+		msgUnknownFormat:      "不明な出力形式です: %s",
+		msgUnknownDivision:    "不明な除算の定義です: %s (truncated, floored, euclidean のいずれか)",
+		msgOneFile:            "ファイルは1つだけ指定してください",
+		msgBadGroup:           "桁区切りの桁数が正しくありません: %s",
+		msgTooLong:            "式が長すぎます (%d バイトまで)",
+		msgBodyTooLarge:       "リクエストの本文が大きすぎます (%d バイトまで)",
+		msgWriteResponse:      "応答を書き込めませんでした: %v",
+		msgTooManyExprs:       "式が多すぎます (%d 個まで)",
+		msgTrailingData:       "JSON の後ろに余分なデータがあります",
+		msgUnknownAlgorithm:   "不明なダイジェストの種類です: %s (crc32, md5, sha1, sha256 のいずれか)",
+		msgChecksumMismatch:   "警告: %d 個のチェックサムが一致しませんでした",
+		msgChecksumUnreadable: "警告: %d 個のファイルを読めませんでした",
+		msgChecksumBadLines:   "警告: %d 行の形式が正しくありません",
+	},
+	"en": {
+		msgSyntax:             "invalid expression",
+		msgSyntaxCause:        "invalid expression: %v",
+		msgSyntaxEOF:          "invalid expression: unexpected end of input",
+		msgSyntaxUnexpected:   "invalid expression: unexpected %q",
+		msgSyntaxBadChar:      "invalid expression: invalid character %q",
+		msgSyntaxBadName:      "invalid expression: cannot assign to this name",
+		msgSyntaxMissing:      "invalid expression: missing %q",
+		msgSyntaxRune:         "invalid expression: rune literal not terminated",
+		msgUnknownOp:          "undefined operator",
+		msgUnknownOpSymbol:    "undefined operator: %s",
+		msgDivByZero:          "division by zero",
+		msgUndefined:          "undefined name",
+		msgUndefinedName:      "undefined name: %s",
+		msgArgs:               "invalid arguments",
+		msgArgsCount:          "invalid arguments: %v takes %d arguments",
+		msgArgsComplex:        "invalid arguments: complex takes real arguments",
+		msgArgsVars:           "invalid arguments: %d variable values are required",
+		msgType:               "wrong kind of value",
+		msgTypeFuncInArith:    "wrong kind of value: a function cannot be used in arithmetic",
+		msgTypeNotCallable:    "wrong kind of value: only functions can be called",
+		msgTypeFuncResult:     "wrong kind of value: the result is a function",
+		msgTypeIntOnly:        "wrong kind of value: div, mod and // take integers only",
+		msgDepth:              "function calls nested too deeply",
+		msgTimeout:            "evaluation did not finish in time",
+		msgOverflow:           "overflow",
+		msgOverflowBinary:     "overflow: %v %s %v (%T)",
+		msgOverflowNeg:        "overflow: -(%v) (%T)",
+		msgNotCompilable:      "cannot compile to bytecode",
+		msgNotCompilableCall:  "cannot compile to bytecode: only builtin functions can be called",
+		msgNotCompilableFunc:  "cannot compile to bytecode: anonymous functions are not supported",
+		msgNotCompilableVar:   "cannot compile to bytecode: %s is bound as a variable, so it cannot be called as a builtin",
+		msgBadOperator:        "cannot register operator",
+		msgBadOperatorEmpty:   "cannot register operator: empty symbol",
+		msgBadOperatorArrow:   "cannot register operator: -> is reserved for anonymous functions",
+		msgBadOperatorEquals:  "cannot register operator: symbols starting with = would clash with let and def",
+		msgBadOperatorRune:    "cannot register operator: %q cannot be used in a symbol",
+		msgBadOperatorImpl:    "cannot register operator: %s has no implementation (%s)",
+		msgBadOperatorPrec:    "cannot register operator: precedence of %s must be at least 1",
+		msgBadOperatorArity:   "cannot register operator: %s must take 1 or 2 operands",
+		msgNegativeExponent:   "negative exponent",
+		msgNegativeShift:      "negative shift count",
+		msgBigLiteral:         "not an integer: %s",
+		msgAt:                 "column %d: %v",
+		msgInside:             "in %v: %v",
+		msgFunction:           "<function %s(%s)>",
+		msgErrorPrefix:        "error: %v",
+		msgUnknownCommand:     "unknown command: %s",
+		msgUnknownMode:        "unknown mode: %s",
+		msgUnknownFormat:      "unknown output format: %s",
+		msgUnknownDivision:    "unknown division mode: %s (one of truncated, floored, euclidean)",
+		msgOneFile:            "specify at most one file",
+		msgBadGroup:           "invalid digit group size: %s",
+		msgTooLong:            "expression too long (at most %d bytes)",
+		msgBodyTooLarge:       "request body too large (at most %d bytes)",
+		msgWriteResponse:      "failed to write response: %v",
+		msgTooManyExprs:       "too many expressions (at most %d)",
+		msgTrailingData:       "unexpected data after JSON value",
+		msgUnknownAlgorithm:   "unknown digest algorithm: %s (one of crc32, md5, sha1, sha256)",
+		msgChecksumMismatch:   "WARNING: %d computed checksum(s) did NOT match",
+		msgChecksumUnreadable: "WARNING: %d listed file(s) could not be read",
+		msgChecksumBadLines:   "WARNING: %d line(s) are improperly formatted",
+	},
+}
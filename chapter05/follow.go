@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"learning-go/internal/catalog"
+	"learning-go/internal/cleanup"
+	"learning-go/internal/fileio"
+)
+
+// ファイルの追記を待ち続けて表示する (example008 の -f。tail -f と同じ)
+//   - ファイルの終わり (io.EOF) に着いても終わらずに，一定の間隔で追記されたかを調べる
+//   - 切り詰め: ファイルが読んだ位置より小さくなったら，先頭から読み直す
+//   - ローテーション: 同じ名前のファイルが別のファイル (inode が違う) に置き換わったら，
+//     元のファイルの残りを読んでから新しいファイルを開き直す
+//     - 名前のファイルが一時的になくなっても (移動した直後など)，また現れるまで待つ
+//       (置き換わったことは1回だけ表示し，開き直せるまで黙って待つ)
+//     - 元のファイルを閉じたときのエラーも返す
+//   - ctx が取り消されたら (Ctrl-C など) エラーなしで終わる
+//   - 圧縮されたファイルは追記されないので，展開はせずにそのまま読む
+
+// 追記を調べる間隔
+const followInterval = 500 * time.Millisecond
+
+func followFile(ctx context.Context, name string, out *catPrinter) (err error) {
+	var c cleanup.Stack
+	defer c.RunInto(&err)
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	c.Push(func() error { return file.Close() }) // 開き直したら新しいファイルを閉じる
+	opened, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	copyToEnd := func() error {
+		err := fileio.ReadChunks(file, 2048, func(chunk []byte) error {
+			_, err := out.Write(chunk)
+			return err
+		})
+		out.w.Flush()
+		return err
+	}
+	replaced := false // 置き換わったことを表示して，新しいファイルを開くのを待っている
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	for {
+		if err := copyToEnd(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := os.Stat(name)
+		if err != nil {
+			continue // ローテーションの途中でなくなっている。現れるまで待つ
+		}
+		if !os.SameFile(opened, current) {
+			if err := copyToEnd(); err != nil { // 置き換わる前に書かれた残り
+				return err
+			}
+			if !replaced { // 開き直せるまで待つ間は，何度も表示しない
+				fmt.Fprintln(os.Stderr, catalog.Message(msgFileReplaced, name))
+				replaced = true
+			}
+			next, err := os.Open(name)
+			if err != nil {
+				continue // Stat の後にまたなくなった。次の間隔で開き直す
+			}
+			// Stat と Open の間にまた置き換わることがあるので，開いたファイル自体の情報を比べる
+			info, err := next.Stat()
+			if err != nil {
+				return errors.Join(err, next.Close())
+			}
+			old := file
+			file, opened, replaced = next, info, false
+			if err := old.Close(); err != nil {
+				return err
+			}
+			continue
+		}
+		offset, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if current.Size() < offset {
+			fmt.Fprintln(os.Stderr, catalog.Message(msgFileTruncated, name))
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -4,7 +4,22 @@
 
 package main
 
+import (
+	"flag"
+	"log"
+	"os"
+
+	"learning-go/internal/catalog"
+)
+
 func main() {
+	// エラーメッセージの言語 (messages.go を参照)
+	lang := flag.String("lang", "", "エラーメッセージの言語 (ja, en)")
+	flag.Parse()
+	if err := catalog.SetLanguage(*lang); err != nil {
+		log.Fatal(err)
+	}
+
 	example001()
 	example002()
 	example003()
@@ -12,6 +27,9 @@ func main() {
 	example005()
 	example006()
 	example007()
-	example008()
+	err := example008() // エラーは example008 の中で出力済み
 	example009()
+	if err != nil {
+		os.Exit(1)
+	}
 }
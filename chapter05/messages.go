@@ -0,0 +1,38 @@
+package main
+
+import "learning-go/internal/catalog"
+
+// メッセージカタログ (このディレクトリで使う文言。文言を引く仕組みは internal/catalog)
+//   - 利用者に見せるエラーの文言はメッセージ ID ごとに日本語 (ja) と英語 (en) を持つ
+
+const (
+	msgDivByZero       catalog.ID = "div_by_zero"
+	msgSyntax          catalog.ID = "syntax"
+	msgUnknownOpSymbol catalog.ID = "unknown_op.symbol"
+	msgNoFile          catalog.ID = "no_file"
+	msgFileTruncated   catalog.ID = "file_truncated"
+	msgFileReplaced    catalog.ID = "file_replaced"
+)
+
+func init() {
+	catalog.Register(messages)
+}
+
+var messages = catalog.Messages{
+	"ja": {
+		msgDivByZero:       "0で割ることはできません",
+		msgSyntax:          "不正な式です",
+		msgUnknownOpSymbol: "定義されていない演算子です: %s",
+		msgNoFile:          "ファイルが指定されていません",
+		msgFileTruncated:   "%s: ファイルが切り詰められました。先頭から読み直します",
+		msgFileReplaced:    "%s: ファイルが置き換えられました。新しいファイルを読みます",
+	},
+	"en": {
+		msgDivByZero:       "division by zero",
+		msgSyntax:          "invalid expression",
+		msgUnknownOpSymbol: "undefined operator: %s",
+		msgNoFile:          "no file specified",
+		msgFileTruncated:   "%s: file truncated; reading from the beginning",
+		msgFileReplaced:    "%s: file replaced; following the new file",
+	},
+}
@@ -6,10 +6,9 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"os"
+
+	"learning-go/internal/fileio"
 )
 
 // T = string ならば func makePointer(s string) *string { return &s } と同じ意味
@@ -274,23 +273,16 @@ func main() {
 	//   - 再利用可能なバッファとして使える
 	//   - データを読み込む度にメモリ割り当てを行うことを避けられる
 	{
+		// fileio.ReadFile がファイルを開いて 100 バイトずつ渡す
+		//   - 100 バイトのバッファはプールから借りて使い回す (fileio.ReadChunks)
+		//   - gzip などで圧縮されていれば展開しながら読む (fileio.Decompress)
+		//   - Close のエラーも返す (internal/cleanup)
+		//   - fileio.MmapThreshold を設定すると，それ以上の大きさのファイルは mmap で読む (バッファへのコピーがなくなる)
 		processFile := func(fileName string) error {
-			file, err := os.Open(fileName)
-			if err != nil {
-				return err
-			}
-			defer file.Close()
-			data := make([]byte, 100)
-			for {
-				_, err := file.Read(data)
-				// process(data[:count]) // 読み込んだデータの処理
-				if err != nil {
-					if errors.Is(err, io.EOF) {
-						return nil
-					}
-					return err
-				}
-			}
+			return fileio.ReadFile(fileName, 100, func(chunk []byte) error {
+				// process(chunk) // 読み込んだデータの処理
+				return nil
+			})
 		}
 
 		err := processFile("main.go")
@@ -0,0 +1,3 @@
+module learning-go
+
+go 1.25
@@ -0,0 +1,121 @@
+// Package catalog は，エラーなどの文言を言語ごとに引くメッセージカタログ
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// メッセージカタログの仕組み (文言そのものは各パッケージが Register で登録する)
+//   - chapter05, chapter05/exercise, chapter06 と，共有のパッケージ (cleanup など) が使う
+//     - 各ディレクトリの messages.go が init で自分の文言を登録する
+//     - ID は1つのプログラムの中で重ならないようにする (共有のパッケージの ID はパッケージ名で始める)
+//   - 言語は -lang フラグ，なければ環境変数 LC_ALL, LC_MESSAGES, LANG の順に決める
+//     - ja で始まれば日本語，それ以外 (C や en_US.UTF-8 など) は英語，何も設定がなければ日本語
+//   - エラーは Error で表し，Error() を呼んだ時点の言語で文字列にする
+//     - センチネルエラーはパッケージの初期化時に作るので，作った時点で文字列にすると言語を切り替えられない
+
+// メッセージ ID
+type ID string
+
+// 言語ごとの，メッセージ ID から fmt の書式への対応
+type Messages map[string]map[ID]string
+
+// 既定の言語 (カタログにない ID はこの言語の文言を使う)
+const defaultLanguage = "ja"
+
+const msgUnknownLanguage ID = "catalog.unknown_language"
+
+var (
+	messages = Messages{
+		"ja": {msgUnknownLanguage: "不明な言語です: %s (ja, en のいずれか)"},
+		"en": {msgUnknownLanguage: "unknown language: %s (one of ja, en)"},
+	}
+	language = detectLanguage(os.Getenv)
+)
+
+// 文言を登録する (同じ言語・同じ ID の文言は後から登録したもので上書きする)
+func Register(m Messages) {
+	for lang, texts := range m {
+		if messages[lang] == nil {
+			messages[lang] = map[ID]string{}
+		}
+		for id, format := range texts {
+			messages[lang][id] = format
+		}
+	}
+}
+
+// 環境変数から言語を決める
+func detectLanguage(getenv func(string) string) string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := getenv(name); locale != "" {
+			if strings.HasPrefix(locale, "ja") {
+				return "ja"
+			}
+			return "en"
+		}
+	}
+	return defaultLanguage
+}
+
+// -lang フラグで言語を選ぶ (空なら環境変数から決めたまま)
+func SetLanguage(lang string) error {
+	if lang == "" {
+		return nil
+	}
+	if _, ok := messages[lang]; !ok {
+		return NewError(msgUnknownLanguage, lang)
+	}
+	language = lang
+	return nil
+}
+
+// メッセージ ID の文言に引数を埋め込む
+func Message(id ID, args ...any) string {
+	format, ok := messages[language][id]
+	if !ok {
+		format, ok = messages[defaultLanguage][id]
+	}
+	if !ok { // カタログにない ID でも何が起きたかはわかるようにする
+		return fmt.Sprint(append([]any{id, ": "}, args...)...)
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// カタログの文言で表すエラー
+//   - kind はエラーの種類を表すセンチネルエラー (センチネル自身なら nil)
+//   - errors.Is / errors.As は kind と，引数のうちエラーであるものをたどる
+type Error struct {
+	kind error
+	id   ID
+	args []any
+}
+
+func (e *Error) Error() string {
+	return Message(e.id, e.args...)
+}
+
+func (e *Error) Unwrap() []error {
+	var errs []error
+	if e.kind != nil {
+		errs = append(errs, e.kind)
+	}
+	for _, arg := range e.args {
+		if err, ok := arg.(error); ok {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// センチネルエラーや，種類を持たないエラーを作る
+func NewError(id ID, args ...any) error {
+	return &Error{id: id, args: args}
+}
+
+// 種類 kind のエラーを作る (errors.Is(err, kind) が true になる)
+func ErrorOf(kind error, id ID, args ...any) error {
+	return &Error{kind: kind, id: id, args: args}
+}
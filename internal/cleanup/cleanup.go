@@ -0,0 +1,78 @@
+// Package cleanup は，後始末の関数を積んでおいてまとめて実行するスタック
+package cleanup
+
+import (
+	"errors"
+	"io"
+
+	"learning-go/internal/catalog"
+)
+
+// 後始末のスタック
+//   - 後始末の関数を積んでおき，defer と同じく後に積んだものから順に実行する (LIFO)
+//   - file.Close() などのエラーを捨てずに，すべて errors.Join でまとめて返す
+//     - 書き込みでは Close のエラーで初めて失敗がわかることがある
+//   - 後始末の関数がパニックしても残りの後始末は続け，パニックはエラーにして返す
+//   - 名前付き戻り値の関数では defer c.RunInto(&err) とすると，本体のエラーに後始末のエラーを加えて返せる
+//   - 一度実行したらスタックは空になるので，2回実行しても同じ関数を2回は呼ばない
+
+const msgPanic catalog.ID = "cleanup.panic"
+
+func init() {
+	catalog.Register(catalog.Messages{
+		"ja": {msgPanic: "後始末の処理でパニックが起きました: %v"},
+		"en": {msgPanic: "panic during cleanup: %v"},
+	})
+}
+
+// 後始末のスタック (ゼロ値は空のスタック)
+type Stack struct {
+	funcs []func() error
+}
+
+func (c *Stack) Push(f func() error) {
+	c.funcs = append(c.funcs, f)
+}
+
+func (c *Stack) PushCloser(closer io.Closer) {
+	c.Push(closer.Close)
+}
+
+// 積んだ関数を逆順に実行し，エラーをまとめて返す
+func (c *Stack) Run() error {
+	var errs []error
+	for len(c.funcs) > 0 {
+		f := c.funcs[len(c.funcs)-1]
+		c.funcs = c.funcs[:len(c.funcs)-1]
+		errs = append(errs, callCleanup(f))
+	}
+	return errors.Join(errs...)
+}
+
+// 名前付き戻り値 err に後始末のエラーを加える (defer c.RunInto(&err) で使う)
+func (c *Stack) RunInto(err *error) {
+	*err = errors.Join(*err, c.Run())
+}
+
+func callCleanup(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r}
+		}
+	}()
+	return f()
+}
+
+// 後始末の関数のパニック (パニックの値がエラーなら errors.Is / errors.As でたどれる)
+type PanicError struct {
+	Value any
+}
+
+func (e *PanicError) Error() string {
+	return catalog.Message(msgPanic, e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
@@ -0,0 +1,63 @@
+// Package division は，整数の商と余りの3つの定義 (truncated, floored, euclidean)
+package division
+
+// 整数の商と余りの定義
+//   - 3つの定義はどれも a == 商 * b + 余り を満たし，余りの符号の決め方だけが違う
+//     - truncated: 商を 0 の方向に切り捨てる (Go の / と %)。余りは a と同じ符号
+//     - floored:   商を負の無限大の方向に切り捨てる (Python の // と %)。余りは b と同じ符号
+//     - euclidean: 余りが常に 0 以上 |b| 未満になるように商を決める
+//   - 例: -7 と 2 なら truncated は (-3, -1)，floored と euclidean は (-4, 1)
+//         -7 と -2 なら truncated と floored は (3, -1)，euclidean は (4, 1)
+//   - 負の数をバケツに分ける・曜日を求めるなどでは floored か euclidean が欲しいことが多い
+//   - chapter05 の example004 と chapter05/exercise の div, mod, // が使う
+
+type Mode int
+
+const (
+	Truncated Mode = iota
+	Floored
+	Euclidean
+)
+
+// すべての定義 (フラグの値の一覧などに使う)
+var Modes = [...]Mode{Truncated, Floored, Euclidean}
+
+var modeNames = [...]string{"truncated", "floored", "euclidean"}
+
+func (m Mode) String() string {
+	return modeNames[m]
+}
+
+// truncated の余り r と割る数 b の符号 (-1, 0, 1) から，mode の定義にするために商に足す数を求める
+//   - 商に d を足したら，余りからは d * b を引く
+func Adjust(rSign, bSign int, mode Mode) int {
+	switch mode {
+	case Floored:
+		if rSign != 0 && rSign != bSign {
+			return -1
+		}
+	case Euclidean:
+		if rSign < 0 {
+			return -bSign
+		}
+	}
+	return 0
+}
+
+// 商と余りを mode の定義で求める
+//   - b が 0 ならパニックする (Go の / と同じ。エラーにするかは呼び出す側が先に調べて決める)
+func QuoRem(a, b int, mode Mode) (int, int) {
+	q, r := a/b, a%b
+	d := Adjust(sign(r), sign(b), mode)
+	return q + d, r - d*b
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
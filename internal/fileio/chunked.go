@@ -0,0 +1,87 @@
+// Package fileio は，chapter05 と chapter06 で共有するファイルの読み込みのヘルパー
+package fileio
+
+import (
+	"io"
+	"math/bits"
+	"sync"
+)
+
+// バッファを使い回してチャンクごとに読む
+//   - ReadChunks は r を size バイトずつ読み，読めたチャンクごとに fn を呼ぶ
+//     - Read と io.EOF の判定のループを1か所にまとめる (fileLen, example008, 6章の processFile で同じ形)
+//     - fn がエラーを返したらそこで読むのをやめ，そのエラーを返す
+//     - チャンクはバッファの一部なので，fn から戻った後は使えない (残すならコピーする)
+//     - size が 0 以下なら一番小さい区分の大きさ (512 バイト) で読む (長さ 0 のバッファでは読み終わらない)
+//   - バッファは大きさの区分 (512 バイトから 1 MiB までの 2 の累乗) ごとの sync.Pool から取る
+//     - 要求より小さくない一番小さい区分のバッファを使い，使い終わったらプールに返す
+//     - 1 MiB を超える大きさはプールせずに毎回割り当てる
+//     - プールには *[]byte を入れる ([]byte を any にすると Put のたびに割り当てが起きる)
+//   - 多くの小さなファイルを読むときに，ファイルごとのバッファの割り当てをなくす (6章の「再利用可能なバッファ」)
+
+const (
+	minBufferShift = 9  // 512 バイト
+	maxBufferShift = 20 // 1 MiB
+)
+
+var bufferPools [maxBufferShift - minBufferShift + 1]sync.Pool
+
+// size バイトを入れられる区分の番号 (プールしない大きさなら -1)
+func bufferClass(size int) int {
+	shift := max(bits.Len(uint(size-1)), minBufferShift)
+	if size <= 0 || shift > maxBufferShift {
+		return -1
+	}
+	return shift - minBufferShift
+}
+
+// 長さ size のバッファを取り出す (容量は区分の大きさ)
+func getBuffer(size int) *[]byte {
+	class := bufferClass(size)
+	if class < 0 {
+		buf := make([]byte, size)
+		return &buf
+	}
+	if p, ok := bufferPools[class].Get().(*[]byte); ok {
+		*p = (*p)[:size]
+		return p
+	}
+	buf := make([]byte, size, 1<<(class+minBufferShift))
+	return &buf
+}
+
+func putBuffer(p *[]byte) {
+	class := bufferClass(cap(*p))
+	if class < 0 || cap(*p) != 1<<(class+minBufferShift) {
+		return
+	}
+	bufferPools[class].Put(p)
+}
+
+// size が 0 以下なら一番小さい区分の大きさにする
+func chunkSize(size int) int {
+	if size <= 0 {
+		return 1 << minBufferShift
+	}
+	return size
+}
+
+func ReadChunks(r io.Reader, size int, fn func(chunk []byte) error) error {
+	p := getBuffer(chunkSize(size))
+	defer putBuffer(p)
+	data := *p
+	for {
+		count, err := r.Read(data)
+		if count > 0 {
+			if err := fn(data[:count]); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package fileio
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// どの大きさでも全体を1回ずつ順に渡し，チャンクが size を超えないか
+func TestReadChunks(t *testing.T) {
+	data := strings.Repeat("abcdefghijklmnopqrstuvwxyz\n", 100)
+	for _, size := range []int{-1, 0, 1, 100, 512, 513, 1 << 20, 2 << 20} {
+		limit := chunkSize(size)
+		// HalfReader は Read が要求の半分しか読まない (短い Read でも最後まで読めるか)
+		for _, r := range []io.Reader{strings.NewReader(data), iotest.HalfReader(strings.NewReader(data))} {
+			var got bytes.Buffer
+			err := ReadChunks(r, size, func(chunk []byte) error {
+				if len(chunk) == 0 || len(chunk) > limit {
+					t.Fatalf("size %d: chunk of %d bytes", size, len(chunk))
+				}
+				got.Write(chunk)
+				return nil
+			})
+			if err != nil || got.String() != data {
+				t.Errorf("size %d: read %d bytes (%v), want %d", size, got.Len(), err, len(data))
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package fileio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"os"
+
+	"learning-go/internal/cleanup"
+)
+
+// 圧縮されたファイルをそのまま読めるようにする
+//   - 先頭のバイト (マジックナンバー) を見て gzip, bzip2, zlib なら展開しながら読む Reader を返す
+//     - gzip: 1f 8b
+//     - bzip2: "BZh" と圧縮の単位 '1' 〜 '9' に，最初のブロックの印 (π の桁 31 41 59 26 53 59) か
+//       終わりの印 (√π の桁 17 72 45 38 50 90。空のデータ) が続く
+//       - 「BZh1 is ...」のような普通の文字で始まるファイルを bzip2 と取り違えない
+//     - zlib: 2バイトのヘッダ (圧縮方式が deflate で，ヘッダを 31 で割り切れる)
+//       - ヘッダは「x^」のような普通の文字にもなるので，先頭を実際に展開できるかも確かめる
+//   - どれでもなければそのまま読む (先読みしたバイトも失われない)
+//   - 後始末の関数は展開する側を閉じてからファイルを閉じる (開いたのと逆の順。internal/cleanup)
+//     - どちらの Close のエラーもまとめて返す
+
+// 判定のために先読みするバイト数
+const sniffLen = 512
+
+func GetFile(name string) (io.Reader, func() error, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	var c cleanup.Stack
+	c.PushCloser(file)
+	r, closeReader, err := Decompress(file)
+	if err != nil {
+		return nil, nil, errors.Join(err, c.Run())
+	}
+	c.Push(closeReader)
+	return r, c.Run, nil
+}
+
+// r が圧縮されていれば展開する Reader を返す
+func Decompress(r io.Reader) (io.Reader, func() error, error) {
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+	switch {
+	case isGzip(head):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, zr.Close, nil
+	case isBzip2(head):
+		return bzip2.NewReader(br), noClose, nil
+	case isZlib(head):
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, nil, err
+		}
+		return zr, zr.Close, nil
+	}
+	return br, noClose, nil
+}
+
+func noClose() error { return nil }
+
+// 先頭のバイトが圧縮の形式のどれかに当てはまるか
+func IsCompressed(head []byte) bool {
+	return isGzip(head) || isBzip2(head) || isZlib(head)
+}
+
+func isGzip(head []byte) bool {
+	return bytes.HasPrefix(head, []byte{0x1f, 0x8b})
+}
+
+var (
+	bzip2BlockMagic = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	bzip2EndMagic   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+func isBzip2(head []byte) bool {
+	if len(head) < 10 || !bytes.HasPrefix(head, []byte("BZh")) || head[3] < '1' || '9' < head[3] {
+		return false
+	}
+	return bytes.HasPrefix(head[4:], bzip2BlockMagic) || bytes.HasPrefix(head[4:], bzip2EndMagic)
+}
+
+func isZlib(head []byte) bool {
+	if len(head) < 2 {
+		return false
+	}
+	cmf, flg := head[0], head[1]
+	if cmf&0x0f != 8 || cmf>>4 > 7 || flg&0x20 != 0 || (uint(cmf)<<8|uint(flg))%31 != 0 {
+		return false
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(head))
+	if err != nil {
+		return false
+	}
+	defer zr.Close()
+	_, err = zr.Read(make([]byte, 1))
+	return err == nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
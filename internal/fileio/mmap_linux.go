@@ -0,0 +1,18 @@
+//go:build linux
+
+package fileio
+
+import (
+	"os"
+	"syscall"
+)
+
+// ファイル全体を読み取り専用でメモリに対応付ける (Linux の mmap)
+//   - Linux 以外では mmap_other.go が errors.ErrUnsupported を返し，ReadChunks の読み込みに戻る
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package fileio
+
+import (
+	"errors"
+	"os"
+)
+
+// Linux 以外では mmap を使わない (mapLargeFile が普通の読み込みに戻す)
+func mapFile(f *os.File, size int) ([]byte, func() error, error) {
+	return nil, nil, errors.ErrUnsupported
+}
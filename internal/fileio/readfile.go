@@ -0,0 +1,129 @@
+package fileio
+
+import (
+	"io"
+	"os"
+
+	"learning-go/internal/cleanup"
+)
+
+// ファイルをチャンクに分けて読む (fileLen や 6章の processFile が使う)
+//   - MmapThreshold バイト以上の普通のファイルは，mmap でメモリに対応付けて読む (Linux のみ)
+//     - 小さなバッファへのコピーがなくなるので，数 GB のファイルで速くなる
+//     - チャンクは対応付けたメモリの一部をそのまま渡す (コピーしない)
+//   - 次の場合は ReadChunks の読み込みに戻る
+//     - MmapThreshold が 0 (既定。mmap は使わない) か，ファイルがそれより小さい
+//     - パイプやデバイスなどの普通のファイルでないもの (大きさが決まらない)
+//     - 圧縮されたファイル (展開しながら読む。decompress.go の Decompress)
+//     - mmap が使えない (Linux 以外や，mmap の失敗)
+//   - 注意: 対応付けている間に他のプロセスがファイルを切り詰めると，読んだときに SIGBUS で落ちる
+//     (ログなどの書き換えられるファイルには使わない)
+
+// このバイト数以上のファイルを mmap で読む (0 なら使わない。wc などの -mmap フラグで設定する)
+var MmapThreshold int64
+
+// name を size バイト以下のチャンクに分けて fn に渡す (size が 0 以下なら ReadChunks と同じく 512 バイト)
+func ReadFile(name string, size int, fn func(chunk []byte) error) error {
+	return ReadFileTee(name, size, nil, fn)
+}
+
+// ReadFile と同じく読みながら，展開する前のファイルのバイトをそのまま raw にも書く (raw が nil なら書かない)
+//   - チェックサムは sha256sum と同じくファイルそのものから求めるため (chapter05/exercise の checksum.go)
+//   - raw には先頭から最後まですべてのバイトが1回ずつ順に書かれる
+//     (展開に使われなかった末尾のバイトも最後に書く)
+//   - raw への書き込みに失敗したら (書けたバイトが足りなくても) そのエラーを返す
+func ReadFileTee(name string, size int, raw io.Writer, fn func(chunk []byte) error) (err error) {
+	var c cleanup.Stack
+	defer c.RunInto(&err)
+	if raw != nil {
+		raw = fullWriter{raw}
+	}
+	size = chunkSize(size) // mmap で読むときも長さ 0 のチャンクを渡し続けない
+	data, unmap, err := mapLargeFile(name)
+	if err != nil {
+		return err
+	}
+	if data != nil {
+		c.Push(unmap)
+		for len(data) > 0 {
+			n := min(size, len(data))
+			if raw != nil {
+				if _, err := raw.Write(data[:n]); err != nil {
+					return err
+				}
+			}
+			if err := fn(data[:n]); err != nil {
+				return err
+			}
+			data = data[n:]
+		}
+		return nil
+	}
+
+	if raw == nil {
+		r, closer, err := GetFile(name)
+		if err != nil {
+			return err
+		}
+		c.Push(closer)
+		return ReadChunks(r, size, fn)
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	c.PushCloser(file)
+	r, closeReader, err := Decompress(io.TeeReader(file, raw))
+	if err != nil {
+		return err
+	}
+	c.Push(closeReader)
+	if err := ReadChunks(r, size, fn); err != nil {
+		return err
+	}
+	_, err = io.Copy(raw, file)
+	return err
+}
+
+// 書けたバイトが足りなければ io.ErrShortWrite にする (io.TeeReader は短い書き込みをエラーにしない)
+type fullWriter struct {
+	w io.Writer
+}
+
+func (f fullWriter) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+// mmap で読むべきファイルなら対応付けたメモリを返す (読むべきでなければ nil)
+//   - 対応付けた後はファイルを閉じてよい (メモリは unmap するまで読める)
+func mapLargeFile(name string) (data []byte, unmap func() error, err error) {
+	if MmapThreshold <= 0 {
+		return nil, nil, nil
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	size := info.Size()
+	if !info.Mode().IsRegular() || size < MmapThreshold || size != int64(int(size)) {
+		return nil, nil, nil
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	data, unmap, err = mapFile(f, int(size))
+	if err != nil {
+		return nil, nil, nil // mmap できなければ普通に読む
+	}
+	if IsCompressed(data[:min(len(data), sniffLen)]) {
+		unmap()
+		return nil, nil, nil
+	}
+	return data, unmap, nil
+}
@@ -0,0 +1,67 @@
+package fileio
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 書き込みに失敗する Writer (n バイトだけ書けたことにして err を返す)
+type failingWriter struct {
+	n   int
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return min(w.n, len(p)), w.err
+}
+
+// mmap で読んでも普通に読んでも，raw への書き込みの失敗を返すか
+func TestReadFileTeeWriteError(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(name, []byte("hello, world\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	errWrite := errors.New("write failed")
+	defer func() { MmapThreshold = 0 }()
+	for _, threshold := range []int64{0, 1} { // 0 なら普通に読む，1 なら mmap で読む
+		MmapThreshold = threshold
+		for _, c := range []struct {
+			w    io.Writer
+			want error
+		}{
+			{failingWriter{0, errWrite}, errWrite},
+			{failingWriter{3, nil}, io.ErrShortWrite},
+		} {
+			err := ReadFileTee(name, 4, c.w, func([]byte) error { return nil })
+			if !errors.Is(err, c.want) {
+				t.Errorf("MmapThreshold %d, %#v: error = %v, want %v", threshold, c.w, err, c.want)
+			}
+		}
+	}
+}
+
+// 大きさが 0 以下でも，mmap で読んでも普通に読んでも読み終わるか
+func TestReadFileNonPositiveSize(t *testing.T) {
+	data := []byte("hello, world\n")
+	name := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(name, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { MmapThreshold = 0 }()
+	for _, threshold := range []int64{0, 1} {
+		MmapThreshold = threshold
+		for _, size := range []int{0, -1} {
+			var got []byte
+			err := ReadFile(name, size, func(chunk []byte) error {
+				got = append(got, chunk...)
+				return nil
+			})
+			if err != nil || string(got) != string(data) {
+				t.Errorf("MmapThreshold %d, size %d: %q (%v), want %q", threshold, size, got, err, data)
+			}
+		}
+	}
+}